@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+// configFile is the name of the project config read from the working directory.
+const configFile = "keploy.yaml"
+
+// projectConfig is the project config loaded for the command, or nil without a config file.
+var projectConfig *models.Config
+
+// loadConfig reads the project config from the path of the --config flag, or from the keploy.yaml
+// of the working directory, and sets the flags of the command which are not passed. The KEPLOY_*
+// environment variables take precedence over the config file, e.g. KEPLOY_API_TIMEOUT for the
+// apiTimeout flag.
+func loadConfig(cmd *cobra.Command, path string, logger *zap.Logger) error {
+	explicit := path != ""
+	if !explicit {
+		path = configFile
+	}
+	cfg, err := yaml.ReadConfig(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			cfg = nil
+		} else {
+			return err
+		}
+	}
+	projectConfig = cfg
+	if cfg != nil {
+		logger.Debug("loaded the project config", zap.Any("path", path))
+	}
+
+	values := configValues(cfg)
+	var setErr error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if setErr != nil || flag.Changed {
+			return
+		}
+		value, ok := os.LookupEnv(envName(flag.Name))
+		if !ok {
+			value, ok = values[flag.Name]
+		}
+		if !ok {
+			return
+		}
+		if err := cmd.Flags().Set(flag.Name, value); err != nil {
+			setErr = fmt.Errorf("failed to set the %s flag from the config. error: %v", flag.Name, err.Error())
+		}
+	})
+	return setErr
+}
+
+// configValues returns the values of the flags set in the project config. The keys missing from
+// the config, and the empty lists, are left to the defaults of the flags.
+func configValues(cfg *models.Config) map[string]string {
+	values := map[string]string{}
+	if cfg == nil {
+		return values
+	}
+	set := func(key, name, value string) {
+		if cfg.Keys[key] {
+			values[name] = value
+		}
+	}
+	// the empty lists can't be set as the values of the flags
+	setList := func(key, name, value string) {
+		if value != "" {
+			set(key, name, value)
+		}
+	}
+	set("path", "path", cfg.Path)
+	set("command", "command", cfg.Command)
+	set("containerName", "containerName", cfg.ContainerName)
+	set("networkName", "networkName", cfg.NetworkName)
+	set("delay", "delay", fmt.Sprint(cfg.Delay))
+	set("apiTimeout", "apiTimeout", fmt.Sprint(cfg.APITimeout))
+	ports := make([]string, len(cfg.PassThroughPorts))
+	for i, port := range cfg.PassThroughPorts {
+		ports[i] = fmt.Sprint(port)
+	}
+	setList("passThroughPorts", "passThroughPorts", strings.Join(ports, ","))
+
+	filter := cfg.Record
+	setList("record.includePaths", "include-path", strings.Join(filter.IncludePaths, ","))
+	setList("record.excludePaths", "exclude-path", strings.Join(filter.ExcludePaths, ","))
+	setList("record.includeMethods", "include-method", strings.Join(filter.IncludeMethods, ","))
+	setList("record.excludeMethods", "exclude-method", strings.Join(filter.ExcludeMethods, ","))
+	setList("record.includeHosts", "include-host", strings.Join(filter.IncludeHosts, ","))
+	setList("record.excludeHosts", "exclude-host", strings.Join(filter.ExcludeHosts, ","))
+	setList("record.includeHeaders", "include-header", joinPairs(filter.IncludeHeaders))
+	setList("record.excludeHeaders", "exclude-header", joinPairs(filter.ExcludeHeaders))
+	set("record.sampleRate", "sample-rate", fmt.Sprint(filter.SampleRate))
+	set("record.maxPerEndpoint", "max-per-endpoint", fmt.Sprint(filter.MaxPerEndpoint))
+	set("record.dedupe", "dedupe", fmt.Sprint(filter.Dedupe))
+
+	redact := cfg.Redact
+	setList("redact.headers", "redact-header", strings.Join(redact.Headers, ","))
+	setList("redact.fields", "redact-field", strings.Join(redact.Fields, ","))
+	setList("redact.patterns", "redact-pattern", joinCSV(redact.Patterns))
+	setList("redact.detectors", "redact-detector", strings.Join(redact.Detectors, ","))
+	set("redact.salt", "redact-salt", redact.Salt)
+	return values
+}
+
+// joinPairs formats the map as the value of a key=value flag, e.g. a=1,b=2.
+func joinPairs(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for key, value := range m {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// joinCSV formats the list as the value of a string slice flag, quoting the values with commas,
+// e.g. the regexes like \d{1,3}.
+func joinCSV(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(list); err != nil {
+		return strings.Join(list, ",")
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// envName returns the environment variable of the flag, e.g. KEPLOY_PASS_THROUGH_PORTS for
+// passThroughPorts and KEPLOY_HEALTH_URL for health-url.
+func envName(flag string) string {
+	var name strings.Builder
+	name.WriteString("KEPLOY_")
+	for i, r := range flag {
+		switch {
+		case r == '-':
+			name.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0 && !unicode.IsUpper(rune(flag[i-1])) && flag[i-1] != '-':
+			name.WriteRune('_')
+			name.WriteRune(r)
+		default:
+			name.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return name.String()
+}
+
+func NewCmdConfig(logger *zap.Logger) *Config {
+	return &Config{
+		logger: logger,
+	}
+}
+
+type Config struct {
+	logger *zap.Logger
+}
+
+func (c *Config) GetCmd() *cobra.Command {
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "manage the keploy.yaml project config",
+	}
+
+	var generateCmd = &cobra.Command{
+		Use:     "generate",
+		Short:   "write the keploy.yaml project config with the default values",
+		Example: `keploy config generate --path .`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				c.logger.Error("Failed to get the path flag", zap.Error((err)))
+				return err
+			}
+
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				c.logger.Error("Failed to get the force flag", zap.Error((err)))
+			}
+
+			file := filepath.Join(path, configFile)
+			if _, err := os.Stat(file); err == nil && !force {
+				c.logger.Error("the config file already exists, use --force to overwrite it", zap.Any("path", file))
+				return errors.New("config file already exists")
+			}
+
+			err = yaml.WriteConfig(file, models.DefaultConfig())
+			if err != nil {
+				c.logger.Error("failed to generate the config file", zap.Error(err))
+				return err
+			}
+			c.logger.Info("generated the config file", zap.Any("path", file))
+			return nil
+		},
+	}
+
+	generateCmd.Flags().StringP("path", "p", ".", "Path to the directory where keploy.yaml is written")
+
+	generateCmd.Flags().Bool("force", false, "Overwrite the existing keploy.yaml")
+
+	generateCmd.SilenceUsage = true
+	generateCmd.SilenceErrors = true
+
+	configCmd.AddCommand(generateCmd)
+
+	return configCmd
+}
+
+// projectTestConfig returns the test config of the project config for all the test sets.
+func projectTestConfig() *models.TestSetConfig {
+	if projectConfig == nil {
+		return nil
+	}
+	return &projectConfig.Test
+}
+
+// projectTestSetConfigs returns the test set configs of the project config.
+func projectTestSetConfigs() map[string]models.TestSetConfig {
+	if projectConfig == nil {
+		return nil
+	}
+	return projectConfig.TestSets
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/service/coverage"
+	"go.uber.org/zap"
+)
+
+func NewCmdCoverage(logger *zap.Logger) *Coverage {
+	reporter := coverage.NewReporter(logger)
+	return &Coverage{
+		reporter: reporter,
+		logger:   logger,
+	}
+}
+
+type Coverage struct {
+	reporter coverage.Reporter
+	logger   *zap.Logger
+}
+
+func (c *Coverage) GetCmd() *cobra.Command {
+	var coverageCmd = &cobra.Command{
+		Use:     "coverage",
+		Short:   "report the API endpoints exercised by the recorded testcases",
+		Example: `keploy coverage --openapi ./openapi.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				c.logger.Error("failed to read the testcase path input")
+				return err
+			}
+
+			//if user provides relative path
+			if len(path) > 0 && path[0] != '/' {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					c.logger.Error("failed to get the absolute path from relative path", zap.Error(err))
+				}
+				path = absPath
+			} else if len(path) == 0 { // if user doesn't provide any path
+				cdirPath, err := os.Getwd()
+				if err != nil {
+					c.logger.Error("failed to get the path of current directory", zap.Error(err))
+				}
+				path = cdirPath
+			}
+
+			path += "/keploy"
+
+			openAPIPath, err := cmd.Flags().GetString("openapi")
+			if err != nil {
+				c.logger.Error("Failed to get the openapi flag", zap.Error((err)))
+			}
+
+			return c.reporter.Report(path, openAPIPath)
+		},
+	}
+
+	coverageCmd.Flags().StringP("path", "p", "", "Path to local directory where generated testcases/mocks are stored")
+
+	coverageCmd.Flags().String("openapi", "", "Path to the OpenAPI spec of the application, to list its untested operations")
+
+	coverageCmd.SilenceUsage = true
+	coverageCmd.SilenceErrors = true
+
+	return coverageCmd
+}
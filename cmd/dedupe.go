@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/service/dedupe"
+	"go.uber.org/zap"
+)
+
+func NewCmdDedupe(logger *zap.Logger) *Dedupe {
+	deduper := dedupe.NewDeduper(logger)
+	return &Dedupe{
+		deduper: deduper,
+		logger:  logger,
+	}
+}
+
+type Dedupe struct {
+	deduper dedupe.Deduper
+	logger  *zap.Logger
+}
+
+func (d *Dedupe) GetCmd() *cobra.Command {
+	var dedupeCmd = &cobra.Command{
+		Use:     "dedupe [test-set...]",
+		Short:   "find the testcases with the same request and response shape as an earlier testcase of their test set",
+		Example: `keploy dedupe test-set-0 --apply`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				d.logger.Error("failed to read the testcase path input")
+				return err
+			}
+
+			//if user provides relative path
+			if len(path) > 0 && path[0] != '/' {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					d.logger.Error("failed to get the absolute path from relative path", zap.Error(err))
+				}
+				path = absPath
+			} else if len(path) == 0 { // if user doesn't provide any path
+				cdirPath, err := os.Getwd()
+				if err != nil {
+					d.logger.Error("failed to get the path of current directory", zap.Error(err))
+				}
+				path = cdirPath
+			}
+
+			path += "/keploy"
+
+			apply, err := cmd.Flags().GetBool("apply")
+			if err != nil {
+				d.logger.Error("Failed to get the apply flag", zap.Error((err)))
+			}
+
+			return d.deduper.Dedupe(path, args, apply)
+		},
+	}
+
+	dedupeCmd.Flags().StringP("path", "p", "", "Path to local directory where generated testcases/mocks are stored")
+
+	dedupeCmd.Flags().Bool("apply", false, "Remove the duplicate testcases instead of only listing them")
+
+	dedupeCmd.SilenceUsage = true
+	dedupeCmd.SilenceErrors = true
+
+	return dedupeCmd
+}
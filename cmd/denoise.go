@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/service/denoise"
+	"go.uber.org/zap"
+)
+
+func NewCmdDenoise(logger *zap.Logger) *Denoise {
+	denoiser := denoise.NewDenoiser(logger)
+	return &Denoise{
+		denoiser: denoiser,
+		logger:   logger,
+	}
+}
+
+type Denoise struct {
+	denoiser denoise.Denoiser
+	logger   *zap.Logger
+}
+
+func (d *Denoise) GetCmd() *cobra.Command {
+	var denoiseCmd = &cobra.Command{
+		Use:     "denoise",
+		Short:   "replay the recorded testcases multiple times and mark the fields which change between the replays as noise",
+		Example: `sudo -E keploy denoise -c "/path/to/user/app" --delay 6 --runs 3`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				d.logger.Error("failed to read the testcase path input")
+				return err
+			}
+
+			//if user provides relative path
+			if len(path) > 0 && path[0] != '/' {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					d.logger.Error("failed to get the absolute path from relative path", zap.Error(err))
+				}
+				path = absPath
+			} else if len(path) == 0 { // if user doesn't provide any path
+				cdirPath, err := os.Getwd()
+				if err != nil {
+					d.logger.Error("failed to get the path of current directory", zap.Error(err))
+				}
+				path = cdirPath
+			}
+
+			path += "/keploy"
+
+			appCmd, err := cmd.Flags().GetString("command")
+			if err != nil {
+				d.logger.Error("Failed to get the command to run the user application", zap.Error((err)))
+			}
+			appContainer, err := cmd.Flags().GetString("containerName")
+			if err != nil {
+				d.logger.Error("Failed to get the application's docker container name", zap.Error((err)))
+			}
+			if appCmd == "" && appContainer == "" {
+				fmt.Println("Error: missing required -c flag")
+				fmt.Println("Example usage:\n", cmd.Example)
+				return errors.New("missing required -c flag")
+			}
+
+			networkName, err := cmd.Flags().GetString("networkName")
+			if err != nil {
+				d.logger.Error("Failed to get the application's docker network name", zap.Error((err)))
+			}
+
+			delay, err := cmd.Flags().GetUint64("delay")
+			if err != nil {
+				d.logger.Error("Failed to get the delay flag", zap.Error((err)))
+			}
+
+			apiTimeout, err := cmd.Flags().GetUint64("apiTimeout")
+			if err != nil {
+				d.logger.Error("Failed to get the apiTimeout flag", zap.Error((err)))
+			}
+
+			ports, err := cmd.Flags().GetUintSlice("passThroughPorts")
+			if err != nil {
+				d.logger.Error("failed to read the ports of outgoing calls to be ignored")
+				return err
+			}
+
+			runs, err := cmd.Flags().GetUint("runs")
+			if err != nil {
+				d.logger.Error("Failed to get the runs flag", zap.Error((err)))
+			}
+
+			d.logger.Info("", zap.Any("keploy test and mock path", path))
+
+			if !d.denoiser.Denoise(path, appCmd, appContainer, networkName, delay, ports, apiTimeout, runs) {
+				return errors.New("failed to denoise the recorded testcases")
+			}
+			return nil
+		},
+	}
+
+	denoiseCmd.Flags().StringP("path", "p", "", "Path to local directory where generated testcases/mocks are stored")
+
+	denoiseCmd.Flags().StringP("command", "c", "", "Command to start the user application")
+
+	denoiseCmd.Flags().String("containerName", "", "Name of the application's docker container")
+
+	denoiseCmd.Flags().StringP("networkName", "n", "", "Name of the application's docker network")
+
+	denoiseCmd.Flags().Uint64P("delay", "d", 5, "User provided time to run its application")
+
+	denoiseCmd.Flags().Uint64("apiTimeout", 5, "User provided timeout for calling its application")
+
+	denoiseCmd.Flags().UintSlice("passThroughPorts", []uint{}, "Ports of Outgoing dependency calls to be ignored as mocks")
+
+	denoiseCmd.Flags().Uint("runs", 3, "Number of times every testcase is replayed to find the noisy fields")
+
+	denoiseCmd.SilenceUsage = true
+	denoiseCmd.SilenceErrors = true
+
+	return denoiseCmd
+}
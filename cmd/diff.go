@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/service/diff"
+	"go.uber.org/zap"
+)
+
+func NewCmdDiff(logger *zap.Logger) *Diff {
+	differ := diff.NewDiffer(logger)
+	return &Diff{
+		differ: differ,
+		logger: logger,
+	}
+}
+
+type Diff struct {
+	differ diff.Differ
+	logger *zap.Logger
+}
+
+func (d *Diff) GetCmd() *cobra.Command {
+	var diffCmd = &cobra.Command{
+		Use:     "diff <setA> <setB>",
+		Short:   "compare the testcases of two test sets and report the changes in the API behaviour",
+		Example: `keploy diff test-set-0 test-set-1 --format json`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				d.logger.Error("failed to read the testcase path input")
+				return err
+			}
+
+			//if user provides relative path
+			if len(path) > 0 && path[0] != '/' {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					d.logger.Error("failed to get the absolute path from relative path", zap.Error(err))
+				}
+				path = absPath
+			} else if len(path) == 0 { // if user doesn't provide any path
+				cdirPath, err := os.Getwd()
+				if err != nil {
+					d.logger.Error("failed to get the path of current directory", zap.Error(err))
+				}
+				path = cdirPath
+			}
+
+			path += "/keploy"
+
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				d.logger.Error("Failed to get the format flag", zap.Error((err)))
+			}
+
+			return d.differ.Diff(path, args[0], args[1], format)
+		},
+	}
+
+	diffCmd.Flags().StringP("path", "p", "", "Path to local directory where generated testcases/mocks are stored")
+
+	diffCmd.Flags().String("format", "text", "Format of the diff: text or json")
+
+	diffCmd.SilenceUsage = true
+	diffCmd.SilenceErrors = true
+
+	return diffCmd
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/models"
 	"go.keploy.io/server/pkg/service/record"
 	"go.uber.org/zap"
 )
@@ -120,7 +121,79 @@ func (r *Record) GetCmd() *cobra.Command {
 	
 			r.logger.Debug("the ports are", zap.Any("ports", ports))
 			// r.recorder.CaptureTraffic(tcsPath, mockPath, appCmd, appContainer, networkName, delay)
-			r.recorder.CaptureTraffic(path, appCmd, appContainer, networkName, delay, ports)
+			includePaths, err := cmd.Flags().GetStringSlice("include-path")
+			if err != nil {
+				r.logger.Error("Failed to get the include-path flag", zap.Error((err)))
+			}
+
+			excludePaths, err := cmd.Flags().GetStringSlice("exclude-path")
+			if err != nil {
+				r.logger.Error("Failed to get the exclude-path flag", zap.Error((err)))
+			}
+
+			includeMethods, err := cmd.Flags().GetStringSlice("include-method")
+			if err != nil {
+				r.logger.Error("Failed to get the include-method flag", zap.Error((err)))
+			}
+
+			excludeMethods, err := cmd.Flags().GetStringSlice("exclude-method")
+			if err != nil {
+				r.logger.Error("Failed to get the exclude-method flag", zap.Error((err)))
+			}
+
+			includeHosts, err := cmd.Flags().GetStringSlice("include-host")
+			if err != nil {
+				r.logger.Error("Failed to get the include-host flag", zap.Error((err)))
+			}
+
+			excludeHosts, err := cmd.Flags().GetStringSlice("exclude-host")
+			if err != nil {
+				r.logger.Error("Failed to get the exclude-host flag", zap.Error((err)))
+			}
+
+			includeHeaders, err := cmd.Flags().GetStringToString("include-header")
+			if err != nil {
+				r.logger.Error("Failed to get the include-header flag", zap.Error((err)))
+			}
+
+			excludeHeaders, err := cmd.Flags().GetStringToString("exclude-header")
+			if err != nil {
+				r.logger.Error("Failed to get the exclude-header flag", zap.Error((err)))
+			}
+
+			sampleRate, err := cmd.Flags().GetFloat64("sample-rate")
+			if err != nil {
+				r.logger.Error("Failed to get the sample-rate flag", zap.Error((err)))
+			}
+
+			maxPerEndpoint, err := cmd.Flags().GetInt("max-per-endpoint")
+			if err != nil {
+				r.logger.Error("Failed to get the max-per-endpoint flag", zap.Error((err)))
+			}
+
+			dedupe, err := cmd.Flags().GetBool("dedupe")
+			if err != nil {
+				r.logger.Error("Failed to get the dedupe flag", zap.Error((err)))
+			}
+
+			redact, err := redactOptions(cmd, r.logger)
+			if err != nil {
+				return err
+			}
+
+			r.recorder.CaptureTraffic(path, appCmd, appContainer, networkName, delay, ports, models.RecordFilter{
+				IncludePaths:   includePaths,
+				ExcludePaths:   excludePaths,
+				IncludeMethods: includeMethods,
+				ExcludeMethods: excludeMethods,
+				IncludeHosts:   includeHosts,
+				ExcludeHosts:   excludeHosts,
+				IncludeHeaders: includeHeaders,
+				ExcludeHeaders: excludeHeaders,
+				SampleRate:     sampleRate,
+				MaxPerEndpoint: maxPerEndpoint,
+				Dedupe:         dedupe,
+			}, redact)
 			return nil
 			// server.Server(version, kServices, conf, logger)
 			// server.Server(version)
@@ -148,6 +221,30 @@ func (r *Record) GetCmd() *cobra.Command {
 
 	// recordCmd.Flags().UintSlice()
 
+	recordCmd.Flags().StringSlice("include-path", []string{}, "Globs of the paths of the requests to be recorded, e.g. /api/**")
+
+	recordCmd.Flags().StringSlice("exclude-path", []string{}, "Globs of the paths of the requests to be skipped, e.g. /healthz,/static/**")
+
+	recordCmd.Flags().StringSlice("include-method", []string{}, "Methods of the requests to be recorded")
+
+	recordCmd.Flags().StringSlice("exclude-method", []string{}, "Methods of the requests to be skipped, e.g. OPTIONS")
+
+	recordCmd.Flags().StringSlice("include-host", []string{}, "Globs of the hosts of the requests to be recorded")
+
+	recordCmd.Flags().StringSlice("exclude-host", []string{}, "Globs of the hosts of the requests to be skipped")
+
+	recordCmd.Flags().StringToString("include-header", map[string]string{}, "Headers with the globs of their values for the requests to be recorded, e.g. X-Record=true")
+
+	recordCmd.Flags().StringToString("exclude-header", map[string]string{}, "Headers with the globs of their values for the requests to be skipped, e.g. User-Agent=kube-probe*")
+
+	recordCmd.Flags().Float64("sample-rate", 1, "Fraction of the matching requests to be recorded, e.g. 0.1")
+
+	recordCmd.Flags().Int("max-per-endpoint", 0, "Maximum number of identical requests to be recorded for an endpoint, unlimited when 0")
+
+	recordCmd.Flags().Bool("dedupe", false, "Skip the testcases with the same request and response shape as an already recorded testcase")
+
+	addRedactFlags(recordCmd)
+
 	recordCmd.SilenceUsage = true
 	recordCmd.SilenceErrors = true
 
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/service/redact"
+	"go.uber.org/zap"
+)
+
+func NewCmdRedact(logger *zap.Logger) *Redact {
+	redactor := redact.NewRedactor(logger)
+	return &Redact{
+		redactor: redactor,
+		logger:   logger,
+	}
+}
+
+type Redact struct {
+	redactor redact.Redactor
+	logger   *zap.Logger
+}
+
+func (r *Redact) GetCmd() *cobra.Command {
+	var redactCmd = &cobra.Command{
+		Use:     "redact [test-set...]",
+		Short:   "replace the secrets in the recorded testcases and mocks with placeholders",
+		Example: `keploy redact test-set-0 --redact-detector all --redact-field user.ssn`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cmd.Flags().GetString("path")
+			if err != nil {
+				r.logger.Error("failed to read the testcase path input")
+				return err
+			}
+
+			//if user provides relative path
+			if len(path) > 0 && path[0] != '/' {
+				absPath, err := filepath.Abs(path)
+				if err != nil {
+					r.logger.Error("failed to get the absolute path from relative path", zap.Error(err))
+				}
+				path = absPath
+			} else if len(path) == 0 { // if user doesn't provide any path
+				cdirPath, err := os.Getwd()
+				if err != nil {
+					r.logger.Error("failed to get the path of current directory", zap.Error(err))
+				}
+				path = cdirPath
+			}
+
+			path += "/keploy"
+
+			opts, err := redactOptions(cmd, r.logger)
+			if err != nil {
+				return err
+			}
+
+			return r.redactor.Redact(path, args, opts)
+		},
+	}
+
+	redactCmd.Flags().StringP("path", "p", "", "Path to local directory where generated testcases/mocks are stored")
+
+	addRedactFlags(redactCmd)
+
+	redactCmd.SilenceUsage = true
+	redactCmd.SilenceErrors = true
+
+	return redactCmd
+}
+
+// addRedactFlags adds the flags of the redaction rules, shared by the record and redact commands.
+func addRedactFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("redact-header", []string{}, "Headers whose values are replaced with placeholders, e.g. Authorization")
+
+	cmd.Flags().StringSlice("redact-field", []string{}, "JSON body, form and query fields whose values are replaced with placeholders, e.g. password,user.ssn")
+
+	cmd.Flags().StringSlice("redact-pattern", []string{}, "Regexes of the secrets replaced with placeholders, only the first group is replaced when the regex has one")
+
+	cmd.Flags().StringSlice("redact-detector", []string{}, "Built-in secret detectors to enable: auth, cookie, password, jwt, aws, private-key, db-url, postgres or all")
+
+	cmd.Flags().String("redact-salt", "", "Salt of the placeholders, so that they can't be matched against guessed secrets")
+}
+
+func redactOptions(cmd *cobra.Command, logger *zap.Logger) (models.RedactOptions, error) {
+	headers, err := cmd.Flags().GetStringSlice("redact-header")
+	if err != nil {
+		logger.Error("Failed to get the redact-header flag", zap.Error((err)))
+		return models.RedactOptions{}, err
+	}
+
+	fields, err := cmd.Flags().GetStringSlice("redact-field")
+	if err != nil {
+		logger.Error("Failed to get the redact-field flag", zap.Error((err)))
+		return models.RedactOptions{}, err
+	}
+
+	patterns, err := cmd.Flags().GetStringSlice("redact-pattern")
+	if err != nil {
+		logger.Error("Failed to get the redact-pattern flag", zap.Error((err)))
+		return models.RedactOptions{}, err
+	}
+
+	detectors, err := cmd.Flags().GetStringSlice("redact-detector")
+	if err != nil {
+		logger.Error("Failed to get the redact-detector flag", zap.Error((err)))
+		return models.RedactOptions{}, err
+	}
+
+	salt, err := cmd.Flags().GetString("redact-salt")
+	if err != nil {
+		logger.Error("Failed to get the redact-salt flag", zap.Error((err)))
+		return models.RedactOptions{}, err
+	}
+
+	return models.RedactOptions{
+		Headers:   headers,
+		Fields:    fields,
+		Patterns:  patterns,
+		Detectors: detectors,
+		Salt:      salt,
+	}, nil
+}
@@ -119,12 +119,34 @@ func (r *Root) execute() {
 	// rootCmd.Flags().IntP("pid", "", 0, "Please enter the process id on which your application is running.")
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Run in debug mode")
 
+	rootCmd.PersistentFlags().String("config", "", "Path to the project config file (default ./keploy.yaml)")
+
+	// the flags which are not passed are set from the env and the project config
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if cmd.Parent() != nil && cmd.Parent().Name() == "config" {
+			return nil
+		}
+		path, err := cmd.Flags().GetString("config")
+		if err != nil {
+			r.logger.Error("Failed to get the config flag", zap.Error((err)))
+			return err
+		}
+		if path == "" {
+			path = os.Getenv("KEPLOY_CONFIG")
+		}
+		err = loadConfig(cmd, path, r.logger)
+		if err != nil {
+			r.logger.Error("failed to load the project config", zap.Error(err))
+		}
+		return err
+	}
+
 	// Manually parse flags to determine debug mode early
 	debugMode = checkForDebugFlag(os.Args[1:])
 	// Now that flags are parsed, set up the l722ogger
 	r.logger = setupLogger()
 
-	r.subCommands = append(r.subCommands, NewCmdRecord(r.logger), NewCmdTest(r.logger), NewCmdServe(r.logger), NewCmdExample(r.logger), NewCmdMockRecord(r.logger), NewCmdMockTest(r.logger))
+	r.subCommands = append(r.subCommands, NewCmdRecord(r.logger), NewCmdTest(r.logger), NewCmdServe(r.logger), NewCmdExample(r.logger), NewCmdMockRecord(r.logger), NewCmdMockTest(r.logger), NewCmdDenoise(r.logger), NewCmdCoverage(r.logger), NewCmdConfig(r.logger), NewCmdDiff(r.logger), NewCmdDedupe(r.logger), NewCmdRedact(r.logger))
 
 	// add the registered keploy plugins as subcommands to the rootCmd
 	for _, sc := range r.subCommands {
@@ -105,7 +105,9 @@ func (t *Test) GetCmd() *cobra.Command {
 				t.logger.Error("Failed to get the delay flag", zap.Error((err)))
 			}
 
-			if delay <= 5 {
+			// the delay is only a fallback when the readiness of the application is checked
+			readinessSet := cmd.Flags().Changed("health-url") || cmd.Flags().Changed("ready-addr") || cmd.Flags().Changed("ready-log")
+			if delay <= 5 && !readinessSet {
 				fmt.Printf("Warning: delay is set to %d seconds, incase your app takes more time to start use --delay to set custom delay\n", delay)
 				if isDockerCmd {
 					fmt.Println("Example usage:\n", `keploy test -c "docker run -p 8080:808 --network myNetworkName --rm myApplicationImageName" --delay 6\n`)
@@ -132,7 +134,132 @@ func (t *Test) GetCmd() *cobra.Command {
 
 			t.logger.Debug("the ports are", zap.Any("ports", ports))
 
-			t.tester.Test(path, testReportPath, appCmd, appContainer, networkName, delay, ports, apiTimeout)
+			parallel, err := cmd.Flags().GetUint("parallel")
+			if err != nil {
+				t.logger.Error("Failed to get the parallel flag", zap.Error((err)))
+			}
+
+			reportFormat, err := cmd.Flags().GetString("report-format")
+			if err != nil {
+				t.logger.Error("Failed to get the report-format flag", zap.Error((err)))
+			}
+
+			update, err := cmd.Flags().GetBool("update")
+			if err != nil {
+				t.logger.Error("Failed to get the update flag", zap.Error((err)))
+			}
+
+			updatePrompt, err := cmd.Flags().GetBool("update-prompt")
+			if err != nil {
+				t.logger.Error("Failed to get the update-prompt flag", zap.Error((err)))
+			}
+
+			strict, err := cmd.Flags().GetBool("strict")
+			if err != nil {
+				t.logger.Error("Failed to get the strict flag", zap.Error((err)))
+			}
+
+			retries, err := cmd.Flags().GetUint("retries")
+			if err != nil {
+				t.logger.Error("Failed to get the retries flag", zap.Error((err)))
+			}
+
+			quarantine, err := cmd.Flags().GetBool("quarantine")
+			if err != nil {
+				t.logger.Error("Failed to get the quarantine flag", zap.Error((err)))
+			}
+
+			freezeTime, err := cmd.Flags().GetBool("freeze-time")
+			if err != nil {
+				t.logger.Error("Failed to get the freeze-time flag", zap.Error((err)))
+			}
+
+			fakeTimeLib, err := cmd.Flags().GetString("faketime-lib")
+			if err != nil {
+				t.logger.Error("Failed to get the faketime-lib flag", zap.Error((err)))
+			}
+
+			healthURL, err := cmd.Flags().GetString("health-url")
+			if err != nil {
+				t.logger.Error("Failed to get the health-url flag", zap.Error((err)))
+			}
+
+			healthStatus, err := cmd.Flags().GetInt("health-status")
+			if err != nil {
+				t.logger.Error("Failed to get the health-status flag", zap.Error((err)))
+			}
+
+			readyAddr, err := cmd.Flags().GetString("ready-addr")
+			if err != nil {
+				t.logger.Error("Failed to get the ready-addr flag", zap.Error((err)))
+			}
+
+			readyLog, err := cmd.Flags().GetString("ready-log")
+			if err != nil {
+				t.logger.Error("Failed to get the ready-log flag", zap.Error((err)))
+			}
+
+			readyTimeout, err := cmd.Flags().GetUint64("ready-timeout")
+			if err != nil {
+				t.logger.Error("Failed to get the ready-timeout flag", zap.Error((err)))
+			}
+
+			load, err := cmd.Flags().GetBool("load")
+			if err != nil {
+				t.logger.Error("Failed to get the load flag", zap.Error((err)))
+			}
+
+			loadRPS, err := cmd.Flags().GetUint("load-rps")
+			if err != nil {
+				t.logger.Error("Failed to get the load-rps flag", zap.Error((err)))
+			}
+			if load && (loadRPS == 0 || loadRPS > test.MaxLoadRPS) {
+				t.logger.Error(fmt.Sprintf("the load-rps flag has to be between 1 and %d", test.MaxLoadRPS), zap.Any("load-rps", loadRPS))
+				return errors.New("invalid load-rps flag")
+			}
+
+			loadDuration, err := cmd.Flags().GetUint64("load-duration")
+			if err != nil {
+				t.logger.Error("Failed to get the load-duration flag", zap.Error((err)))
+			}
+
+			loadConcurrency, err := cmd.Flags().GetUint("load-concurrency")
+			if err != nil {
+				t.logger.Error("Failed to get the load-concurrency flag", zap.Error((err)))
+			}
+
+			compareDefaultHeaders, err := cmd.Flags().GetBool("compare-default-headers")
+			if err != nil {
+				t.logger.Error("Failed to get the compare-default-headers flag", zap.Error((err)))
+			}
+
+			t.tester.Test(path, testReportPath, appCmd, appContainer, networkName, delay, ports, apiTimeout, test.Option{
+				Parallel:     parallel,
+				ReportFormat: reportFormat,
+				Update:       update || updatePrompt,
+				UpdatePrompt: updatePrompt,
+				Strict:       strict,
+				Retries:      retries,
+				Quarantine:   quarantine,
+				FreezeTime:   freezeTime,
+				FakeTimeLib:  fakeTimeLib,
+				Readiness: test.ReadinessProbe{
+					HealthURL:    healthURL,
+					HealthStatus: healthStatus,
+					TCPAddr:      readyAddr,
+					LogPattern:   readyLog,
+					Timeout:      readyTimeout,
+				},
+				Load: test.LoadOptions{
+					Enabled:     load,
+					RPS:         loadRPS,
+					Duration:    loadDuration,
+					Concurrency: loadConcurrency,
+				},
+				CompareDefaultHeaders: compareDefaultHeaders,
+				Config:                projectTestConfig(),
+				TestSetConfigs:        projectTestSetConfigs(),
+			})
 			return nil
 		},
 	}
@@ -154,6 +281,44 @@ func (t *Test) GetCmd() *cobra.Command {
 
 	testCmd.Flags().UintSlice("passThroughPorts", []uint{}, "Ports of Outgoing dependency calls to be ignored as mocks")
 
+	testCmd.Flags().Uint("parallel", 1, "Number of testcases of a test set to run concurrently")
+
+	testCmd.Flags().String("report-format", "yaml", "Format of the test reports: yaml or junit")
+
+	testCmd.Flags().Bool("update", false, "Update the failing testcases with the actual responses of the application")
+
+	testCmd.Flags().Bool("update-prompt", false, "Ask before updating every failing testcase with the actual response")
+
+	testCmd.Flags().Bool("strict", false, "Fail the testcases whose outgoing calls don't match the mocks or which leave their mocks unused")
+
+	testCmd.Flags().Uint("retries", 0, "Number of times a failing testcase is run again before it is marked as failed")
+
+	testCmd.Flags().Bool("quarantine", false, "Don't fail the test run for the failing testcases which are flaky in the recent test runs")
+
+	testCmd.Flags().Bool("freeze-time", false, "Send the recorded time of the testcases in the KEPLOY_TEST_TIME header for the SDKs to use as the current time")
+
+	testCmd.Flags().String("faketime-lib", "", "Path of libfaketime to set the clock of the natively launched application to the recorded time of the testcases")
+
+	testCmd.Flags().String("health-url", "", "Health url of the application to poll before running the testcases, instead of waiting for the delay")
+
+	testCmd.Flags().Int("health-status", 200, "Status code of the health url when the application is ready")
+
+	testCmd.Flags().String("ready-addr", "", "Address on which the application listens when it is ready, e.g. localhost:8080")
+
+	testCmd.Flags().String("ready-log", "", "Regex of the log line written by the application when it is ready")
+
+	testCmd.Flags().Uint64("ready-timeout", 60, "Seconds to wait for the application to be ready")
+
+	testCmd.Flags().Bool("load", false, "Replay the testcases of every test set as load after running them, and write the latencies in a load report")
+
+	testCmd.Flags().Uint("load-rps", 10, "Number of requests per second sent in the load run")
+
+	testCmd.Flags().Uint64("load-duration", 30, "Seconds for which the requests are sent in the load run")
+
+	testCmd.Flags().Uint("load-concurrency", 0, "Maximum number of requests in flight in the load run, the requests over it are dropped (default load-rps)")
+
+	testCmd.Flags().Bool("compare-default-headers", false, "Compare the headers which are ignored by default as well, as the earlier versions of keploy did: "+strings.Join(test.DefaultIgnoredHeaders, ", ")+". A test set config sets it with headers.noDefaults")
+
 	testCmd.SilenceUsage = true
 	testCmd.SilenceErrors = true
 
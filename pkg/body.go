@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// EncodeBody returns the body as text when it is valid utf-8, or else as base64 encoded binary.
+func EncodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(body)
+}
+
+// DecodeBody returns the raw body from its text or base64 encoded binary form.
+func DecodeBody(text, binary string) ([]byte, error) {
+	if binary == "" {
+		return []byte(text), nil
+	}
+	body, err := base64.StdEncoding.DecodeString(binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the binary body. error: %v", err)
+	}
+	return body, nil
+}
+
+// DecodeMultipartForm decodes the multipart/form-data body into its parts, in their order, along
+// with their headers. The file parts keep the names of the files in Paths along with their
+// Contents. It returns nil if the body is not multipart/form-data.
+func DecodeMultipartForm(contentType string, body []byte) ([]models.FormData, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, nil
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	var form []models.FormData
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the multipart form. error: %v", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the part of the multipart form. error: %v", err)
+		}
+		header := map[string]string{}
+		for key, values := range part.Header {
+			header[key] = strings.Join(values, ", ")
+		}
+		if part.FileName() == "" {
+			form = append(form, models.FormData{Key: part.FormName(), Values: []string{string(content)}, Header: header})
+			continue
+		}
+		form = append(form, models.FormData{
+			Key:      part.FormName(),
+			Paths:    []string{filepath.Base(part.FileName())},
+			Header:   header,
+			Contents: [][]byte{content},
+		})
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// EncodeMultipartForm encodes the form into a multipart/form-data body, using the boundary
+// of the content type and the recorded headers of the parts so that the body is the same as
+// the recorded one.
+func EncodeMultipartForm(contentType string, form []models.FormData, vars map[string]string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["boundary"] != "" {
+		if err := writer.SetBoundary(params["boundary"]); err != nil {
+			return nil, fmt.Errorf("invalid boundary of the multipart form. error: %v", err)
+		}
+	}
+	for _, field := range form {
+		for _, value := range field.Values {
+			header := partHeader(field.Header)
+			if header == nil {
+				header = textproto.MIMEHeader{}
+				header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(field.Key)))
+			}
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := part.Write([]byte(SubstituteVars(value, vars))); err != nil {
+				return nil, err
+			}
+		}
+		for i, path := range field.Paths {
+			if i >= len(field.Contents) {
+				return nil, fmt.Errorf("the contents of the uploaded file %s are not loaded", path)
+			}
+			header := partHeader(field.Header)
+			if header == nil {
+				header = textproto.MIMEHeader{}
+				header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+					quoteEscaper.Replace(field.Key), quoteEscaper.Replace(filepath.Base(path))))
+				header.Set("Content-Type", "application/octet-stream")
+			}
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := part.Write(field.Contents[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// partHeader returns the recorded header of the parts, or nil if it wasn't recorded.
+func partHeader(header map[string]string) textproto.MIMEHeader {
+	if len(header) == 0 {
+		return nil
+	}
+	res := textproto.MIMEHeader{}
+	for key, value := range header {
+		res.Set(key, value)
+	}
+	return res
+}
+
+// RequestBody rebuilds the body of the recorded request from its multipart form, binary or
+// text form, with the variables substituted in its text.
+func RequestBody(req models.HttpReq, vars map[string]string) ([]byte, error) {
+	switch {
+	case len(req.Form) > 0:
+		return EncodeMultipartForm(ToHttpHeader(req.Header).Get("Content-Type"), req.Form, vars)
+	case req.Binary != "":
+		return DecodeBody("", req.Binary)
+	}
+	return []byte(SubstituteVars(req.Body, vars)), nil
+}
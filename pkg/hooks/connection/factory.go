@@ -23,6 +23,8 @@ type Factory struct {
 	inactivityThreshold time.Duration
 	mutex               *sync.RWMutex
 	logger              *zap.Logger
+	// filter decides which ingress requests are recorded, all of them when it is nil
+	filter *Filter
 }
 
 // NewFactory creates a new instance of the factory.
@@ -35,6 +37,13 @@ func NewFactory(inactivityThreshold time.Duration, logger *zap.Logger) *Factory
 	}
 }
 
+// SetFilter sets the filter of the ingress requests recorded as testcases.
+func (factory *Factory) SetFilter(filter *Filter) {
+	factory.mutex.Lock()
+	defer factory.mutex.Unlock()
+	factory.filter = filter
+}
+
 // func (factory *Factory) HandleReadyConnections(k *keploy.Keploy) {
 // func (factory *Factory) HandleReadyConnections(path string, db platform.TestCaseDB, getDeps func() []*models.Mock, resetDeps func() int) {
 func (factory *Factory) HandleReadyConnections(db platform.TestCaseDB) {
@@ -43,27 +52,36 @@ func (factory *Factory) HandleReadyConnections(db platform.TestCaseDB) {
 	defer factory.mutex.Unlock()
 	var trackersToDelete []structs.ConnID
 	for connID, tracker := range factory.connections {
+		// the data events of the tracker are added while the connections are handled
+		isGrpc := tracker.IsGrpc()
 		if tracker.IsComplete() {
 			trackersToDelete = append(trackersToDelete, connID)
-			if len(tracker.SentBuf) == 0 && len(tracker.RecvBuf) == 0 {
+			if isGrpc {
+				factory.handleGrpc(db, tracker)
 				continue
 			}
-			parsedHttpReq, err := pkg.ParseHTTPRequest(tracker.RecvBuf)
+			recvBuf, sentBuf := tracker.ToBytes()
+			if len(sentBuf) == 0 && len(recvBuf) == 0 {
+				continue
+			}
+			parsedHttpReq, err := pkg.ParseHTTPRequest(recvBuf)
 			if err != nil {
 				factory.logger.Error("failed to parse the http request from byte array", zap.Error(err))
 				continue
 			}
-			parsedHttpRes, err := pkg.ParseHTTPResponse(tracker.SentBuf, parsedHttpReq)
+			parsedHttpRes, err := pkg.ParseHTTPResponse(sentBuf, parsedHttpReq)
 			if err != nil {
 				factory.logger.Error("failed to parse the http response from byte array", zap.Error(err))
 				continue
 			}
 
+			events := streamEvents(sentBuf, tracker.marks(), parsedHttpRes)
+
 			switch models.GetMode() {
 			case models.MODE_RECORD:
 				// capture the ingress call for record cmd
 				factory.logger.Debug("capturing ingress call from tracker in record mode")
-				capture(db, parsedHttpReq, parsedHttpRes, factory.logger)
+				capture(db, parsedHttpReq, parsedHttpRes, events, tracker.reqTimestamp, tracker.respTimestamp, factory.filter, factory.logger)
 			case models.MODE_TEST:
 				factory.logger.Debug("skipping tracker in test mode")
 			default:
@@ -72,7 +90,19 @@ func (factory *Factory) HandleReadyConnections(db platform.TestCaseDB) {
 			}
 
 		} else if tracker.Malformed() || tracker.IsInactive(factory.inactivityThreshold) {
+			// capture the gRPC calls completed before the connection went idle
+			if isGrpc {
+				factory.handleGrpc(db, tracker)
+			}
 			trackersToDelete = append(trackersToDelete, connID)
+		} else if !isGrpc && tracker.IsInactive(streamIdleThreshold) {
+			// the streamed responses may be kept open by the app after their last event
+			if factory.captureOpenStream(db, tracker) {
+				trackersToDelete = append(trackersToDelete, connID)
+			}
+		} else if isGrpc {
+			// gRPC connections are long lived, capture the calls completed so far.
+			factory.handleGrpc(db, tracker)
 		}
 	}
 
@@ -95,7 +125,35 @@ func (factory *Factory) GetOrCreate(connectionID structs.ConnID) *Tracker {
 	return tracker
 }
 
-func capture(db platform.TestCaseDB, req *http.Request, resp *http.Response, logger *zap.Logger) {
+// captureOpenStream captures the streamed response of the connection which is still open. It
+// returns false if the response is not streamed.
+func (factory *Factory) captureOpenStream(db platform.TestCaseDB, tracker *Tracker) bool {
+	recvBuf, sentBuf := tracker.ToBytes()
+	if len(sentBuf) == 0 {
+		return false
+	}
+	parsedHttpReq, err := pkg.ParseHTTPRequest(recvBuf)
+	if err != nil {
+		return false
+	}
+	parsedHttpRes, err := pkg.ParseHTTPResponse(sentBuf, parsedHttpReq)
+	if err != nil {
+		return false
+	}
+	events := streamEvents(sentBuf, tracker.marks(), parsedHttpRes)
+	if events == nil {
+		return false
+	}
+	if models.GetMode() == models.MODE_RECORD {
+		factory.logger.Debug("capturing the streamed ingress call of the open connection in record mode")
+		capture(db, parsedHttpReq, parsedHttpRes, events, tracker.reqTimestamp, tracker.respTimestamp, factory.filter, factory.logger)
+	}
+	return true
+}
+
+// capture records the ingress call as a testcase, unless the filter skips its request. The
+// events of a streamed response are recorded instead of its body.
+func capture(db platform.TestCaseDB, req *http.Request, resp *http.Response, events []models.HttpStreamEvent, reqTimestamp, respTimestamp time.Time, filter *Filter, logger *zap.Logger) {
 	// meta := map[string]string{
 	// 	"method": req.Method,
 	// }
@@ -112,11 +170,18 @@ func capture(db platform.TestCaseDB, req *http.Request, resp *http.Response, log
 	}
 
 	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("failed to read the http response body", zap.Error(err))
+	if !filter.Allow(req, reqBody) {
+		logger.Debug("skipping the ingress call filtered out from the record", zap.Any("method", req.Method), zap.Any("url", req.URL.String()))
 		return
 	}
+	var respBody []byte
+	if events == nil {
+		respBody, err = io.ReadAll(resp.Body)
+		if err != nil {
+			logger.Error("failed to read the http response body", zap.Error(err))
+			return
+		}
+	}
 
 	// Encode the message into yaml
 	// mocks := getDeps()
@@ -128,28 +193,46 @@ func capture(db platform.TestCaseDB, req *http.Request, resp *http.Response, log
 	// }
 
 	// err = db.Insert(httpMock, getDeps())
+	httpReq := models.HttpReq{
+		Method:     models.Method(req.Method),
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		// URL:        req.URL.String(),
+		// URL: fmt.Sprintf("%s://%s%s?%s", req.URL.Scheme, req.Host, req.URL.Path, req.URL.RawQuery),
+		URL: fmt.Sprintf("http://%s%s", req.Host, req.URL.RequestURI()),
+		//  URL: string(b),
+		Header:    pkg.ToYamlHttpHeader(req.Header),
+		URLParams: pkg.UrlParams(req),
+		Timestamp: reqTimestamp,
+	}
+	// the uploaded files are stored next to the testcase, and the binary bodies as base64
+	form, err := pkg.DecodeMultipartForm(req.Header.Get("Content-Type"), reqBody)
+	if err != nil {
+		logger.Warn("failed to decode the multipart form of the http request, recording it as the body", zap.Error(err))
+	}
+	if form != nil {
+		httpReq.Form = form
+	} else {
+		httpReq.Body, httpReq.Binary = pkg.EncodeBody(reqBody)
+	}
+	httpResp := models.HttpResp{
+		StatusCode:   resp.StatusCode,
+		Header:       pkg.ResponseHeader(resp),
+		Timestamp:    respTimestamp,
+		StreamEvents: events,
+	}
+	httpResp.Body, httpResp.Binary = pkg.EncodeBody(respBody)
+	if !reqTimestamp.IsZero() && respTimestamp.After(reqTimestamp) {
+		httpResp.Latency = respTimestamp.Sub(reqTimestamp).Milliseconds()
+	}
+
 	err = db.WriteTestcase(&models.TestCase{
-		Version: models.V1Beta2,
-		Name:    "",
-		Kind:    models.HTTP,
-		Created: time.Now().Unix(),
-		HttpReq: models.HttpReq{
-			Method:     models.Method(req.Method),
-			ProtoMajor: req.ProtoMajor,
-			ProtoMinor: req.ProtoMinor,
-			// URL:        req.URL.String(),
-			// URL: fmt.Sprintf("%s://%s%s?%s", req.URL.Scheme, req.Host, req.URL.Path, req.URL.RawQuery),
-			URL: fmt.Sprintf("http://%s%s", req.Host, req.URL.RequestURI()),
-			//  URL: string(b),
-			Header:    pkg.ToYamlHttpHeader(req.Header),
-			Body:      string(reqBody),
-			URLParams: pkg.UrlParams(req),
-		},
-		HttpResp: models.HttpResp{
-			StatusCode: resp.StatusCode,
-			Header:     pkg.ToYamlHttpHeader(resp.Header),
-			Body:       string(respBody),
-		},
+		Version:  models.V1Beta2,
+		Name:     "",
+		Kind:     models.HTTP,
+		Created:  time.Now().Unix(),
+		HttpReq:  httpReq,
+		HttpResp: httpResp,
 		// Mocks: mocks,
 	})
 	if err != nil {
@@ -0,0 +1,177 @@
+package connection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// Filter decides which ingress http requests are recorded as testcases, by the rules of the
+// record filter.
+type Filter struct {
+	includePaths, excludePaths     []*regexp.Regexp
+	includeMethods, excludeMethods []string
+	includeHosts, excludeHosts     []*regexp.Regexp
+	includeHeaders, excludeHeaders map[string]*regexp.Regexp
+	sampleRate                     float64
+	maxPerEndpoint                 int
+
+	mu sync.Mutex
+	// recorded counts the identical requests recorded so far
+	recorded map[string]int
+}
+
+// NewFilter compiles the globs of the record filter.
+func NewFilter(cfg models.RecordFilter) (*Filter, error) {
+	f := &Filter{
+		includeMethods: upper(cfg.IncludeMethods),
+		excludeMethods: upper(cfg.ExcludeMethods),
+		sampleRate:     cfg.SampleRate,
+		maxPerEndpoint: cfg.MaxPerEndpoint,
+		recorded:       map[string]int{},
+	}
+	var err error
+	if f.includePaths, err = compileGlobs(cfg.IncludePaths, true); err != nil {
+		return nil, err
+	}
+	if f.excludePaths, err = compileGlobs(cfg.ExcludePaths, true); err != nil {
+		return nil, err
+	}
+	if f.includeHosts, err = compileGlobs(cfg.IncludeHosts, false); err != nil {
+		return nil, err
+	}
+	if f.excludeHosts, err = compileGlobs(cfg.ExcludeHosts, false); err != nil {
+		return nil, err
+	}
+	if f.includeHeaders, err = compileHeaderGlobs(cfg.IncludeHeaders); err != nil {
+		return nil, err
+	}
+	if f.excludeHeaders, err = compileHeaderGlobs(cfg.ExcludeHeaders); err != nil {
+		return nil, err
+	}
+	if f.sampleRate < 0 || f.sampleRate > 1 {
+		return nil, fmt.Errorf("the sample rate %v is not between 0 and 1", f.sampleRate)
+	}
+	return f, nil
+}
+
+// Allow checks whether the request is recorded. The request is counted towards the limit of
+// identical requests of its endpoint when it is allowed.
+func (f *Filter) Allow(req *http.Request, body []byte) bool {
+	if f == nil {
+		return true
+	}
+	path, host := req.URL.Path, req.Host
+	if hostname, _, ok := strings.Cut(host, ":"); ok {
+		host = hostname
+	}
+
+	if len(f.includePaths) > 0 && !matchAny(f.includePaths, path) ||
+		len(f.includeMethods) > 0 && !contains(f.includeMethods, req.Method) ||
+		len(f.includeHosts) > 0 && !matchAny(f.includeHosts, host) ||
+		len(f.includeHeaders) > 0 && !matchHeaders(f.includeHeaders, req.Header) {
+		return false
+	}
+	if matchAny(f.excludePaths, path) ||
+		contains(f.excludeMethods, req.Method) ||
+		matchAny(f.excludeHosts, host) ||
+		matchHeaders(f.excludeHeaders, req.Header) {
+		return false
+	}
+	if f.sampleRate > 0 && f.sampleRate < 1 && rand.Float64() >= f.sampleRate {
+		return false
+	}
+	if f.maxPerEndpoint <= 0 {
+		return true
+	}
+
+	sum := sha256.Sum256(body)
+	key := req.Method + " " + req.URL.RequestURI() + " " + hex.EncodeToString(sum[:])
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.recorded[key] >= f.maxPerEndpoint {
+		return false
+	}
+	f.recorded[key]++
+	return true
+}
+
+// compileGlobs compiles the globs into regexes. For the paths, * matches within a segment and
+// ** across the segments. Otherwise, * matches any text.
+func compileGlobs(globs []string, path bool) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		pattern := regexp.QuoteMeta(glob)
+		pattern = strings.ReplaceAll(pattern, `\*\*`, "\x00")
+		if path {
+			pattern = strings.ReplaceAll(pattern, `\*`, "[^/]*")
+		} else {
+			pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+		}
+		pattern = strings.ReplaceAll(pattern, "\x00", ".*")
+		pattern = strings.ReplaceAll(pattern, `\?`, ".")
+		re, err := regexp.Compile("^" + pattern + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %s. error: %v", glob, err.Error())
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func compileHeaderGlobs(headers map[string]string) (map[string]*regexp.Regexp, error) {
+	res := map[string]*regexp.Regexp{}
+	for name, glob := range headers {
+		globs, err := compileGlobs([]string{glob}, false)
+		if err != nil {
+			return nil, err
+		}
+		res[http.CanonicalHeaderKey(name)] = globs[0]
+	}
+	return res, nil
+}
+
+func matchAny(globs []*regexp.Regexp, s string) bool {
+	for _, re := range globs {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHeaders checks whether any of the headers has a value matching its glob.
+func matchHeaders(globs map[string]*regexp.Regexp, header http.Header) bool {
+	for name, re := range globs {
+		for _, value := range header.Values(name) {
+			if re.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func upper(list []string) []string {
+	res := make([]string, len(list))
+	for i, v := range list {
+		res[i] = strings.ToUpper(v)
+	}
+	return res
+}
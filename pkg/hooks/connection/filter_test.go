@@ -0,0 +1,170 @@
+package connection
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestFilterAllow(t *testing.T) {
+	type request struct {
+		method, url, body string
+		header            http.Header
+	}
+	get := func(url string) request { return request{method: http.MethodGet, url: url} }
+
+	tests := []struct {
+		name string
+		cfg  models.RecordFilter
+		reqs []request
+		want []bool
+	}{
+		{
+			name: "no rules",
+			reqs: []request{get("http://api.local/users"), {method: http.MethodPost, url: "http://api.local/"}},
+			want: []bool{true, true},
+		},
+		{
+			name: "single star matches within a segment",
+			cfg:  models.RecordFilter{IncludePaths: []string{"/users/*"}},
+			reqs: []request{get("http://api.local/users/1"), get("http://api.local/users/1/orders"), get("http://api.local/users")},
+			want: []bool{true, false, false},
+		},
+		{
+			name: "double star matches across segments",
+			cfg:  models.RecordFilter{IncludePaths: []string{"/users/**"}},
+			reqs: []request{get("http://api.local/users/1"), get("http://api.local/users/1/orders"), get("http://api.local/orders")},
+			want: []bool{true, true, false},
+		},
+		{
+			name: "question mark matches a single character",
+			cfg:  models.RecordFilter{IncludePaths: []string{"/v?/users"}},
+			reqs: []request{get("http://api.local/v1/users"), get("http://api.local/v10/users")},
+			want: []bool{true, false},
+		},
+		{
+			name: "excluded paths win over the included ones",
+			cfg:  models.RecordFilter{IncludePaths: []string{"/**"}, ExcludePaths: []string{"/health*"}},
+			reqs: []request{get("http://api.local/healthz"), get("http://api.local/users")},
+			want: []bool{false, true},
+		},
+		{
+			name: "methods are case insensitive",
+			cfg:  models.RecordFilter{IncludeMethods: []string{"get", "post"}, ExcludeMethods: []string{"Post"}},
+			reqs: []request{get("http://api.local/"), {method: http.MethodPost, url: "http://api.local/"}, {method: http.MethodDelete, url: "http://api.local/"}},
+			want: []bool{true, false, false},
+		},
+		{
+			name: "hosts are matched without the port",
+			cfg:  models.RecordFilter{IncludeHosts: []string{"*.example.com"}, ExcludeHosts: []string{"admin.example.com"}},
+			reqs: []request{get("http://api.example.com:8080/"), get("http://admin.example.com/"), get("http://localhost/")},
+			want: []bool{true, false, false},
+		},
+		{
+			name: "headers",
+			cfg: models.RecordFilter{
+				IncludeHeaders: map[string]string{"content-type": "application/*"},
+				ExcludeHeaders: map[string]string{"User-Agent": "*bot*"},
+			},
+			reqs: []request{
+				{method: http.MethodGet, url: "http://api.local/", header: http.Header{"Content-Type": {"application/json"}}},
+				{method: http.MethodGet, url: "http://api.local/", header: http.Header{"Content-Type": {"text/html"}}},
+				{method: http.MethodGet, url: "http://api.local/", header: http.Header{"Content-Type": {"application/json"}, "User-Agent": {"googlebot/2.1"}}},
+				get("http://api.local/"),
+			},
+			want: []bool{true, false, false, false},
+		},
+		{
+			name: "sample rate of zero records every request",
+			cfg:  models.RecordFilter{SampleRate: 0},
+			reqs: []request{get("http://api.local/"), get("http://api.local/"), get("http://api.local/")},
+			want: []bool{true, true, true},
+		},
+		{
+			name: "sample rate of one records every request",
+			cfg:  models.RecordFilter{SampleRate: 1},
+			reqs: []request{get("http://api.local/"), get("http://api.local/"), get("http://api.local/")},
+			want: []bool{true, true, true},
+		},
+		{
+			name: "max per endpoint counts the identical requests",
+			cfg:  models.RecordFilter{MaxPerEndpoint: 2},
+			reqs: []request{
+				get("http://api.local/users?page=1"),
+				get("http://api.local/users?page=1"),
+				get("http://api.local/users?page=1"),
+				get("http://api.local/users?page=2"),
+				{method: http.MethodPost, url: "http://api.local/users", body: `{"name":"a"}`},
+				{method: http.MethodPost, url: "http://api.local/users", body: `{"name":"b"}`},
+				{method: http.MethodPost, url: "http://api.local/users", body: `{"name":"a"}`},
+				{method: http.MethodPost, url: "http://api.local/users", body: `{"name":"a"}`},
+			},
+			want: []bool{true, true, false, true, true, true, true, false},
+		},
+		{
+			name: "rejected requests are not counted",
+			cfg:  models.RecordFilter{MaxPerEndpoint: 1, ExcludeHeaders: map[string]string{"X-Skip": "true"}},
+			reqs: []request{
+				{method: http.MethodGet, url: "http://api.local/", header: http.Header{"X-Skip": {"true"}}},
+				get("http://api.local/"),
+				get("http://api.local/"),
+			},
+			want: []bool{false, true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []bool
+			for _, r := range tt.reqs {
+				req, err := http.NewRequest(r.method, r.url, strings.NewReader(r.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.header != nil {
+					req.Header = r.header
+				}
+				got = append(got, f.Allow(req, []byte(r.body)))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterSampleRate(t *testing.T) {
+	tests := []struct {
+		rate    float64
+		wantErr bool
+	}{
+		{rate: -0.1, wantErr: true},
+		{rate: 0},
+		{rate: 0.5},
+		{rate: 1},
+		{rate: 1.5, wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := NewFilter(models.RecordFilter{SampleRate: tt.rate})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewFilter() with the sample rate %v returned the error %v, want error %v", tt.rate, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNilFilterAllow(t *testing.T) {
+	var f *Filter
+	req, err := http.NewRequest(http.MethodGet, "http://api.local/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Allow(req, nil) {
+		t.Errorf("Allow() of a nil filter = false, want true")
+	}
+}
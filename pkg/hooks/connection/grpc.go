@@ -0,0 +1,262 @@
+package connection
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform"
+	"go.keploy.io/server/pkg/proxy/integrations/grpcparser"
+)
+
+const (
+	frameHeaderLen = 9
+	// maxFrameSize is the largest frame size which the peers can agree on
+	maxFrameSize = 1<<24 - 1
+)
+
+// isGrpc checks whether the ingress connection starts with the HTTP/2 client preface.
+// The app only speaks cleartext HTTP/2 (h2c) on the ingress side when it is a gRPC server.
+func isGrpc(recvBuf []byte) bool {
+	return bytes.HasPrefix(recvBuf, []byte(http2.ClientPreface))
+}
+
+// IsGrpc checks whether the tracker is of a gRPC connection. The client preface is dropped
+// from the buffer along with the parsed frames, so the connections being parsed are gRPC ones.
+func (conn *Tracker) IsGrpc() bool {
+	if conn.grpc != nil {
+		return true
+	}
+	recvBuf, _ := conn.ToBytes()
+	return isGrpc(recvBuf)
+}
+
+// grpcState is the state of parsing the http2 frames of a gRPC connection. It is kept across
+// the passes over the live connection, so that every pass only parses the frames received
+// since the last pass.
+type grpcState struct {
+	sic        *grpcparser.StreamInfoCollection
+	recv, sent grpcFrames
+	// unparseable is set once the frames can't be parsed, since the header compression
+	// contexts are out of sync with the peers after that
+	unparseable bool
+}
+
+// grpcFrames is the state of parsing one direction of a gRPC connection.
+type grpcFrames struct {
+	// offset is the length of the buffer parsed so far, up to the last complete frame
+	offset int
+	// every direction of a connection has its own header compression context
+	decoder *hpack.Decoder
+	// payloads accumulate the DATA frames of the streams since a message can span multiple frames
+	payloads map[uint32][]byte
+	// headers is the header block being continued by the CONTINUATION frames
+	headers *headerBlock
+}
+
+// headerBlock is a header block split across a HEADERS frame and the CONTINUATION frames following it.
+type headerBlock struct {
+	streamID  uint32
+	endStream bool
+	fragment  []byte
+}
+
+func newGrpcState() *grpcState {
+	return &grpcState{
+		sic: grpcparser.NewStreamInfoCollection(nil),
+		recv: grpcFrames{
+			offset:   len(http2.ClientPreface),
+			decoder:  grpcparser.NewDecoder(),
+			payloads: map[uint32][]byte{},
+		},
+		sent: grpcFrames{
+			decoder:  grpcparser.NewDecoder(),
+			payloads: map[uint32][]byte{},
+		},
+	}
+}
+
+// handleGrpc captures the gRPC calls of the connection whose responses are complete.
+// gRPC clients keep their connections open, so it is called on every pass for live
+// connections as well, and only parses the frames received since the last pass.
+func (factory *Factory) handleGrpc(db platform.TestCaseDB, tracker *Tracker) {
+	recvBuf, sentBuf := tracker.ToBytes()
+	if tracker.grpc == nil {
+		tracker.grpc = newGrpcState()
+	}
+	state := tracker.grpc
+	if state.unparseable {
+		// the data of the connection can't be captured anymore
+		tracker.discard(len(recvBuf), len(sentBuf))
+		return
+	}
+
+	streams, err := state.parse(recvBuf, sentBuf)
+	if err != nil {
+		factory.logger.Error("failed to parse the http2 frames of the gRPC connection, skipping the rest of its calls", zap.Error(err))
+		state.unparseable = true
+	}
+	// the parsed frames aren't needed anymore, so that the buffers of the long lived
+	// connections don't grow without bound
+	tracker.discard(state.recv.offset, state.sent.offset)
+	state.recv.offset, state.sent.offset = 0, 0
+
+	for _, stream := range streams {
+		switch models.GetMode() {
+		case models.MODE_RECORD:
+			factory.logger.Debug("capturing gRPC ingress call from tracker in record mode", zap.Any("stream id", stream.StreamID))
+			captureGrpc(db, stream, factory.filter, factory.logger)
+		case models.MODE_TEST:
+			factory.logger.Debug("skipping tracker in test mode")
+		default:
+			factory.logger.Warn("Keploy mode is not set to record or test. Tracker is being skipped.",
+				zap.Any("current mode", models.GetMode()))
+		}
+	}
+}
+
+// parse reassembles the new http2 frames sent by the client and the app into gRPC streams,
+// and returns the streams whose trailers have been sent by the app since the last pass.
+func (state *grpcState) parse(recvBuf, sentBuf []byte) ([]models.GrpcStream, error) {
+	if len(recvBuf) < state.recv.offset {
+		return nil, nil
+	}
+	_, err := state.recv.read(recvBuf, true, state.sic)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := state.sent.read(sentBuf, false, state.sic)
+
+	streams := []models.GrpcStream{}
+	for _, streamID := range completed {
+		state.sic.AddPayloadForRequest(streamID, state.recv.payloads[streamID])
+		state.sic.AddPayloadForResponse(streamID, state.sent.payloads[streamID])
+		streams = append(streams, state.sic.FetchStream(streamID))
+
+		// the stream is done, so its state isn't needed anymore
+		state.sic.ResetStream(streamID)
+		delete(state.recv.payloads, streamID)
+		delete(state.sent.payloads, streamID)
+	}
+	return streams, err
+}
+
+// read reads the complete frames of the buffer after the offset. It returns the ids of the
+// streams ended by the app, in the order in which they ended.
+func (frames *grpcFrames) read(buf []byte, isReqFromClient bool, sic *grpcparser.StreamInfoCollection) ([]uint32, error) {
+	var completed []uint32
+	for len(buf)-frames.offset >= frameHeaderLen {
+		header := buf[frames.offset:]
+		length := int(header[0])<<16 | int(header[1])<<8 | int(header[2])
+		end := frames.offset + frameHeaderLen + length
+		// the last frame may still be in flight for live connections
+		if end > len(buf) {
+			break
+		}
+		framer := http2.NewFramer(io.Discard, bytes.NewReader(buf[frames.offset:end]))
+		framer.SetMaxReadFrameSize(maxFrameSize)
+		// every frame is read by a new framer, so the order of the CONTINUATION frames is checked below
+		framer.AllowIllegalReads = true
+		frames.offset = end
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			return completed, err
+		}
+		if _, ok := frame.(*http2.ContinuationFrame); !ok && frames.headers != nil {
+			return completed, fmt.Errorf("got %s frame of the stream %d while the headers of the stream %d are continued",
+				frame.Header().Type, frame.Header().StreamID, frames.headers.streamID)
+		}
+
+		switch f := frame.(type) {
+		case *http2.HeadersFrame:
+			frames.headers = &headerBlock{
+				streamID:  f.StreamID,
+				endStream: f.StreamEnded(),
+				fragment:  append([]byte{}, f.HeaderBlockFragment()...),
+			}
+			if f.HeadersEnded() {
+				completed, err = frames.endHeaders(isReqFromClient, sic, completed)
+			}
+		case *http2.ContinuationFrame:
+			if frames.headers == nil || frames.headers.streamID != f.StreamID {
+				return completed, fmt.Errorf("unexpected CONTINUATION frame of the stream %d", f.StreamID)
+			}
+			frames.headers.fragment = append(frames.headers.fragment, f.HeaderBlockFragment()...)
+			if f.HeadersEnded() {
+				completed, err = frames.endHeaders(isReqFromClient, sic, completed)
+			}
+		case *http2.DataFrame:
+			frames.payloads[f.StreamID] = append(frames.payloads[f.StreamID], f.Data()...)
+		}
+		if err != nil {
+			return completed, err
+		}
+	}
+	return completed, nil
+}
+
+// endHeaders decodes the complete header block of a stream and adds its headers to the stream.
+// The id of the stream is appended to the completed streams if the app ended it.
+func (frames *grpcFrames) endHeaders(isReqFromClient bool, sic *grpcparser.StreamInfoCollection, completed []uint32) ([]uint32, error) {
+	block := frames.headers
+	frames.headers = nil
+	pseudoHeaders, ordinaryHeaders, err := grpcparser.ExtractHeaderBlock(block.fragment, frames.decoder)
+	if err != nil {
+		return completed, err
+	}
+	if isReqFromClient {
+		sic.AddHeadersForRequest(block.streamID, pseudoHeaders, true)
+		sic.AddHeadersForRequest(block.streamID, ordinaryHeaders, false)
+		return completed, nil
+	}
+	// If this is the last fragment of a stream from the app, it has to be a trailer.
+	sic.AddHeadersForResponse(block.streamID, pseudoHeaders, true, block.endStream)
+	sic.AddHeadersForResponse(block.streamID, ordinaryHeaders, false, block.endStream)
+	if block.endStream {
+		completed = append(completed, block.streamID)
+	}
+	return completed, nil
+}
+
+func captureGrpc(db platform.TestCaseDB, stream models.GrpcStream, filter *Filter, logger *zap.Logger) {
+	req := grpcRequest(stream.GrpcReq)
+	if !filter.Allow(req, []byte(stream.GrpcReq.Body.DecodedData)) {
+		logger.Debug("skipping the gRPC ingress call filtered out from the record", zap.Any("path", req.URL.Path))
+		return
+	}
+	err := db.WriteTestcase(&models.TestCase{
+		Version:  models.V1Beta2,
+		Name:     "",
+		Kind:     models.GRPC_EXPORT,
+		Created:  time.Now().Unix(),
+		GrpcReq:  stream.GrpcReq,
+		GrpcResp: stream.GrpcResp,
+	})
+	if err != nil {
+		logger.Error("failed to record the gRPC ingress request", zap.Error(err))
+		return
+	}
+}
+
+// grpcRequest returns the http request of the gRPC call, to filter the gRPC calls by the same
+// rules as the http requests.
+func grpcRequest(grpcReq models.GrpcReq) *http.Request {
+	req := &http.Request{
+		Method: grpcReq.Headers.PseudoHeaders[":method"],
+		Host:   grpcReq.Headers.PseudoHeaders[":authority"],
+		URL:    &url.URL{Path: grpcReq.Headers.PseudoHeaders[":path"]},
+		Header: http.Header{},
+	}
+	for key, value := range grpcReq.Headers.OrdinaryHeaders {
+		req.Header.Set(key, value)
+	}
+	return req
+}
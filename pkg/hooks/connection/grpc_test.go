@@ -0,0 +1,166 @@
+package connection
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"go.keploy.io/server/pkg/hooks/structs"
+)
+
+// grpcPeer writes the http2 frames of one direction of a gRPC connection.
+type grpcPeer struct {
+	buf     bytes.Buffer
+	framer  *http2.Framer
+	encoder *hpack.Encoder
+	block   bytes.Buffer
+}
+
+func newGrpcPeer(preface bool) *grpcPeer {
+	p := &grpcPeer{}
+	if preface {
+		p.buf.WriteString(http2.ClientPreface)
+	}
+	p.framer = http2.NewFramer(&p.buf, nil)
+	p.encoder = hpack.NewEncoder(&p.block)
+	return p
+}
+
+// headers writes the header block of the stream, split in as many frames as there are fields.
+func (p *grpcPeer) headers(t *testing.T, streamID uint32, endStream bool, fields ...[2]string) {
+	var fragments [][]byte
+	for _, field := range fields {
+		p.block.Reset()
+		if err := p.encoder.WriteField(hpack.HeaderField{Name: field[0], Value: field[1]}); err != nil {
+			t.Fatal(err)
+		}
+		fragments = append(fragments, append([]byte{}, p.block.Bytes()...))
+	}
+	err := p.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: fragments[0],
+		EndStream:     endStream,
+		EndHeaders:    len(fragments) == 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fragment := range fragments[1:] {
+		if err := p.framer.WriteContinuation(streamID, i == len(fragments)-2, fragment); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (p *grpcPeer) data(t *testing.T, streamID uint32, message string) {
+	payload := append([]byte{0, 0, 0, 0, byte(len(message))}, message...)
+	if err := p.framer.WriteData(streamID, false, payload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGrpcStateParse(t *testing.T) {
+	client, app := newGrpcPeer(true), newGrpcPeer(false)
+	client.headers(t, 1, false, [2]string{":method", "POST"}, [2]string{":path", "/users.Users/Get"}, [2]string{"content-type", "application/grpc"})
+	client.data(t, 1, "req")
+	app.headers(t, 1, false, [2]string{":status", "200"})
+	app.data(t, 1, "resp")
+	// the trailers are split across a HEADERS and two CONTINUATION frames
+	app.headers(t, 1, true, [2]string{"grpc-status", "0"}, [2]string{"grpc-message", ""}, [2]string{"x-trace", "abc"})
+
+	recvBuf, sentBuf := client.buf.Bytes(), app.buf.Bytes()
+	state := newGrpcState()
+	// the frames arrive in two passes, with the last one cut in the middle
+	streams, err := state.parse(recvBuf, sentBuf[:len(sentBuf)-3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 0 {
+		t.Fatalf("parse() of the incomplete stream = %v, want no streams", streams)
+	}
+	streams, err = state.parse(recvBuf, sentBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 1 {
+		t.Fatalf("parse() = %d streams, want 1", len(streams))
+	}
+
+	stream := streams[0]
+	if got := stream.GrpcReq.Headers.PseudoHeaders[":path"]; got != "/users.Users/Get" {
+		t.Errorf("request path = %q, want %q", got, "/users.Users/Get")
+	}
+	wantTrailers := map[string]string{"grpc-status": "0", "grpc-message": "", "x-trace": "abc"}
+	if got := stream.GrpcResp.Trailers.OrdinaryHeaders; !reflect.DeepEqual(got, wantTrailers) {
+		t.Errorf("response trailers = %v, want %v", got, wantTrailers)
+	}
+	if state.recv.offset != len(recvBuf) || state.sent.offset != len(sentBuf) {
+		t.Errorf("parsed offsets = %d and %d, want %d and %d", state.recv.offset, state.sent.offset, len(recvBuf), len(sentBuf))
+	}
+}
+
+func TestGrpcStateParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(t *testing.T, app *grpcPeer)
+	}{
+		{
+			name: "CONTINUATION frame without headers",
+			write: func(t *testing.T, app *grpcPeer) {
+				if err := app.framer.WriteContinuation(1, true, []byte{0x88}); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "other frame in the middle of a header block",
+			write: func(t *testing.T, app *grpcPeer) {
+				err := app.framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: []byte{0x88}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				app.data(t, 1, "resp")
+			},
+		},
+		{
+			name: "header block which can't be decoded",
+			write: func(t *testing.T, app *grpcPeer) {
+				err := app.framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: []byte{0xff}, EndHeaders: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, app := newGrpcPeer(true), newGrpcPeer(false)
+			client.headers(t, 1, false, [2]string{":method", "POST"})
+			tt.write(t, app)
+			if _, err := newGrpcState().parse(client.buf.Bytes(), app.buf.Bytes()); err == nil {
+				t.Errorf("parse() returned no error")
+			}
+		})
+	}
+}
+
+func TestTrackerDiscard(t *testing.T) {
+	tracker := NewTracker(structs.ConnID{}, nil)
+	tracker.RecvBuf = append(tracker.RecvBuf, "abcdef"...)
+	tracker.SentBuf = append(tracker.SentBuf, "ghijkl"...)
+	tracker.sentMarks = []sentMark{{end: 6}}
+
+	tracker.discard(4, 0)
+	recvBuf, sentBuf := tracker.ToBytes()
+	if string(recvBuf) != "ef" || string(sentBuf) != "ghijkl" || len(tracker.marks()) != 1 {
+		t.Errorf("buffers after discard(4, 0) = %q and %q with %d marks", recvBuf, sentBuf, len(tracker.marks()))
+	}
+	tracker.discard(2, 6)
+	recvBuf, sentBuf = tracker.ToBytes()
+	if len(recvBuf) != 0 || len(sentBuf) != 0 || tracker.marks() != nil {
+		t.Errorf("buffers after discard(2, 6) = %q and %q with %d marks", recvBuf, sentBuf, len(tracker.marks()))
+	}
+}
@@ -0,0 +1,142 @@
+package connection
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+)
+
+// streamThreshold is the minimum duration over which the chunks of a chunked response are sent,
+// for the response to be recorded as a stream of chunks instead of a single body.
+const streamThreshold = time.Second
+
+// streamIdleThreshold is the time after the last event of a streamed response on an open
+// connection, after which the response is recorded.
+const streamIdleThreshold = 5 * time.Second
+
+type sentMark struct {
+	end int
+	at  time.Time
+}
+
+// bodyPiece is a part of the response body, along with the offset in SentBuf where it ends.
+type bodyPiece struct {
+	data   []byte
+	rawEnd int
+}
+
+// streamEvents splits the response body in SentBuf into the events of a Server-Sent Events
+// response, or the chunks of a chunked response, with their delays since the start of the
+// response. The body may be incomplete for the streams which are still open. It returns nil if
+// the response is not streamed.
+func streamEvents(sentBuf []byte, marks []sentMark, resp *http.Response) []models.HttpStreamEvent {
+	headerEnd := bytes.Index(sentBuf, []byte("\r\n\r\n"))
+	if headerEnd < 0 || len(marks) == 0 {
+		return nil
+	}
+	headerEnd += 4
+	sse := pkg.IsEventStream(resp.Header.Get("Content-Type"))
+	chunked := len(resp.TransferEncoding) > 0 && resp.TransferEncoding[0] == "chunked"
+	if !sse && !chunked {
+		return nil
+	}
+
+	var pieces []bodyPiece
+	if chunked {
+		pieces = decodeChunks(sentBuf, headerEnd)
+	} else {
+		// the events are sent by the writes of the app
+		start := headerEnd
+		for _, m := range marks {
+			if m.end > start {
+				pieces = append(pieces, bodyPiece{data: sentBuf[start:m.end], rawEnd: m.end})
+				start = m.end
+			}
+		}
+	}
+
+	begin := marks[0].at
+	delay := func(rawEnd int) int64 {
+		for _, m := range marks {
+			if m.end >= rawEnd {
+				return m.at.Sub(begin).Milliseconds()
+			}
+		}
+		return marks[len(marks)-1].at.Sub(begin).Milliseconds()
+	}
+
+	var events []models.HttpStreamEvent
+	if !sse {
+		for _, p := range pieces {
+			events = append(events, models.HttpStreamEvent{Data: string(p.data), Delay: delay(p.rawEnd)})
+		}
+		// the chunks sent at once are the usual body of a large response
+		if len(events) < 2 || time.Duration(events[len(events)-1].Delay)*time.Millisecond < streamThreshold {
+			return nil
+		}
+		return events
+	}
+
+	// the events are separated by blank lines, and an event may span the pieces
+	var (
+		pending strings.Builder
+		lines   []string
+	)
+	for _, p := range pieces {
+		pending.Write(p.data)
+		text := pending.String()
+		for {
+			i := strings.IndexByte(text, '\n')
+			if i < 0 {
+				break
+			}
+			line := strings.TrimRight(text[:i], "\r")
+			text = text[i+1:]
+			if line == "" && len(lines) > 0 {
+				events = append(events, models.HttpStreamEvent{Data: strings.Join(lines, "\n"), Delay: delay(p.rawEnd)})
+				lines = nil
+			} else if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		pending.Reset()
+		pending.WriteString(text)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return events
+}
+
+// decodeChunks decodes the chunked body starting at offset, tolerating an incomplete body.
+func decodeChunks(buf []byte, offset int) []bodyPiece {
+	var pieces []bodyPiece
+	for offset < len(buf) {
+		lineEnd := bytes.Index(buf[offset:], []byte("\r\n"))
+		if lineEnd < 0 {
+			break
+		}
+		sizeText := string(buf[offset : offset+lineEnd])
+		// the chunk extensions are ignored
+		if i := strings.IndexByte(sizeText, ';'); i >= 0 {
+			sizeText = sizeText[:i]
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(sizeText), 16, 64)
+		if err != nil || size == 0 {
+			break
+		}
+		start := offset + lineEnd + 2
+		end := start + int(size)
+		if end > len(buf) {
+			end = len(buf)
+		}
+		pieces = append(pieces, bodyPiece{data: buf[start:end], rawEnd: end})
+		offset = end + 2
+	}
+	return pieces
+}
@@ -29,8 +29,20 @@ type Tracker struct {
 
 	RecvBuf []byte
 	SentBuf []byte
-	mutex   sync.RWMutex
-	logger  *zap.Logger
+
+	// time of the first ingress and the last egress data events of the connection
+	reqTimestamp  time.Time
+	respTimestamp time.Time
+
+	// grpc is the state of parsing the frames of the gRPC connection
+	grpc *grpcState
+
+	// sentMarks are the lengths of SentBuf after every egress data event along with their
+	// times, to find the delays of the events of the streamed responses.
+	sentMarks []sentMark
+
+	mutex  sync.RWMutex
+	logger *zap.Logger
 }
 
 func NewTracker(connID structs2.ConnID, logger *zap.Logger) *Tracker {
@@ -49,6 +61,27 @@ func (conn *Tracker) ToBytes() ([]byte, []byte) {
 	return conn.RecvBuf, conn.SentBuf
 }
 
+// discard drops the first recv and sent bytes of the buffers once they are parsed.
+func (conn *Tracker) discard(recv, sent int) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if recv > 0 {
+		conn.RecvBuf = append([]byte{}, conn.RecvBuf[recv:]...)
+	}
+	if sent > 0 {
+		conn.SentBuf = append([]byte{}, conn.SentBuf[sent:]...)
+		// the marks are only used to find the events of the streamed http responses
+		conn.sentMarks = nil
+	}
+}
+
+// marks returns the lengths of the sent buffer after every egress data event along with their times.
+func (conn *Tracker) marks() []sentMark {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	return conn.sentMarks
+}
+
 func (conn *Tracker) IsInactive(duration time.Duration) bool {
 	conn.mutex.RLock()
 	defer conn.mutex.RUnlock()
@@ -103,9 +136,14 @@ func (conn *Tracker) AddDataEvent(event structs2.SocketDataEvent) {
 	case structs2.EgressTraffic:
 		conn.SentBuf = append(conn.SentBuf, event.Msg[:event.MsgSize]...)
 		conn.sentBytes += uint64(event.MsgSize)
+		conn.respTimestamp = time.Now()
+		conn.sentMarks = append(conn.sentMarks, sentMark{end: len(conn.SentBuf), at: conn.respTimestamp})
 	case structs2.IngressTraffic:
 		conn.RecvBuf = append(conn.RecvBuf, event.Msg[:event.MsgSize]...)
 		conn.recvBytes += uint64(event.MsgSize)
+		if conn.reqTimestamp.IsZero() {
+			conn.reqTimestamp = time.Now()
+		}
 	default:
 	}
 }
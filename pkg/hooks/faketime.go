@@ -0,0 +1,47 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// EnableFakeTime preloads libfaketime into the user application launched natively, so that the
+// time of the application is set to the recorded time of every testcase by SetFakeTime. It has
+// to be called before launching the user application.
+func (h *Hook) EnableFakeTime(lib string) error {
+	if _, err := os.Stat(lib); err != nil {
+		return fmt.Errorf(Emoji+"libfaketime is not found at %s. error: %v", lib, err)
+	}
+	file, err := os.CreateTemp("", "keploy-faketime-")
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to create the file for the fake time. error: %v", err)
+	}
+	file.Close()
+	h.fakeTimeLib = lib
+	h.fakeTimeFile = file.Name()
+	return nil
+}
+
+// DisableFakeTime removes the file of the fake time. The user applications launched after it
+// use the real time.
+func (h *Hook) DisableFakeTime() {
+	if h.fakeTimeFile == "" {
+		return
+	}
+	os.Remove(h.fakeTimeFile)
+	h.fakeTimeLib = ""
+	h.fakeTimeFile = ""
+}
+
+// SetFakeTime sets the clock of the user application to t, from where it keeps ticking. It
+// is a no-op unless the fake time is enabled.
+func (h *Hook) SetFakeTime(t time.Time) error {
+	if h.fakeTimeFile == "" {
+		return nil
+	}
+	// libfaketime counts an absolute time starting with @ from the start of the process, so
+	// the clock is set by its offset from the real time instead.
+	spec := fmt.Sprintf("%+d", int64(time.Until(t).Seconds()))
+	return os.WriteFile(h.fakeTimeFile, []byte(spec), 0644)
+}
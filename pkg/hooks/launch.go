@@ -3,6 +3,7 @@ package hooks
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -244,7 +245,7 @@ func (h *Hook) processDockerEnv(appCmd, appContainer, appNetwork string) error {
 
 					if containerFound {
 						h.logger.Debug(fmt.Sprintf("the user application container pid: %v", containerPid))
-						inode := getInodeNumber(containerPid)
+						inode := getInodeNumber(containerPid, h.logger)
 						h.logger.Debug("", zap.Any("user inode", inode))
 
 						// send the inode of the container to ebpf hooks to filter the network traffic
@@ -355,6 +356,12 @@ func (h *Hook) processDockerEnv(appCmd, appContainer, appNetwork string) error {
 	return nil
 }
 
+// SetAppLogWriter copies the stdout and stderr of the user applications launched afterwards
+// to w, along with the terminal. A nil writer stops copying.
+func (h *Hook) SetAppLogWriter(w io.Writer) {
+	h.appLogs = w
+}
+
 // It runs the application using the given command
 func (h *Hook) runApp(appCmd string, isDocker bool) error {
 	// Create a new command with your appCmd
@@ -364,6 +371,18 @@ func (h *Hook) runApp(appCmd string, isDocker bool) error {
 	// Set the output of the command
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if h.appLogs != nil {
+		cmd.Stdout = io.MultiWriter(os.Stdout, h.appLogs)
+		cmd.Stderr = io.MultiWriter(os.Stderr, h.appLogs)
+	}
+	if h.fakeTimeFile != "" && !isDocker {
+		cmd.Env = append(os.Environ(),
+			"LD_PRELOAD="+h.fakeTimeLib,
+			"FAKETIME_TIMESTAMP_FILE="+h.fakeTimeFile,
+			// the time is read from the file on every call, since it is set for every testcase
+			"FAKETIME_NO_CACHE=1",
+		)
+	}
 	h.userAppCmd = cmd
 
 	h.logger.Debug("", zap.Any("executing cmd", cmd.String()))
@@ -443,13 +462,13 @@ func makeDockerClient() *client.Client {
 	return cli
 }
 
-func getInodeNumber(pid int) uint64 {
+func getInodeNumber(pid int, logger *zap.Logger) uint64 {
 
 	filepath := filepath.Join("/proc", strconv.Itoa(pid), "ns", "pid")
 
 	f, err := os.Stat(filepath)
 	if err != nil {
-		fmt.Errorf("%v failed to get the inode number or namespace Id:", Emoji, err)
+		logger.Error("failed to get the inode number or namespace Id", zap.Error(err))
 		return 0
 	}
 	// Dev := (f.Sys().(*syscall.Stat_t)).Dev
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -53,6 +54,27 @@ type Hook struct {
 	userAppCmd    *exec.Cmd
 	mainRoutineId int
   
+	// depCalls are the outgoing calls of the user application, recorded to verify the
+	// dependencies of the testcases in strict mode
+	recordDepCalls bool
+	depCalls       []models.DepCall
+	// reuseMocks keeps the testcase mocks after they respond to the outgoing calls, so that
+	// the testcases can be replayed repeatedly
+	reuseMocks bool
+	// mockSets are the mocks of the testcases in flight, when the mocks are isolated per
+	// testcase
+	mockSets map[string]*mockSet
+
+	// recordFilter decides which ingress requests are recorded as testcases
+	recordFilter *connection.Filter
+
+	// fakeTimeLib and fakeTimeFile make the natively launched user application read its clock
+	// from libfaketime during the test run
+	fakeTimeLib  string
+	fakeTimeFile string
+	// appLogs receives the stdout and stderr of the user application launched by keploy
+	appLogs io.Writer
+
 	// ebpf objects and events
 	stopper  chan os.Signal
 	socket   link.Link
@@ -116,15 +138,15 @@ func (h *Hook) GetProxyPort() uint32 {
 }
 
 func (h *Hook) GetDepsSize() int {
-	h.mu.Lock()
-	size := len(h.tcsMocks)
-	defer h.mu.Unlock()
-	return size
+	return len(h.GetTcsMocks())
 }
 
 func (h *Hook) AppendMocks(m *models.Mock) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if m.Spec.Timestamp.IsZero() {
+		m.Spec.Timestamp = time.Now()
+	}
 	// h.tcsMocks = append(h.tcsMocks, m)
 	err := h.TestCaseDB.WriteMock(m)
 	if err != nil {
@@ -139,6 +161,75 @@ func (h *Hook) SetTcsMocks(m []*models.Mock) {
 	defer h.mu.Unlock()
 }
 
+// DeleteTcsMock removes the mock from the testcase mocks, once it has been used to respond
+// to an outgoing call. It returns false if the mock has already been removed. The mock is
+// kept while the mocks are reused.
+func (h *Hook) DeleteTcsMock(m *models.Mock) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.reuseMocks {
+		return true
+	}
+	if i := indexOf(h.tcsMocks, m); i != -1 {
+		h.tcsMocks = without(h.tcsMocks, i)
+		return true
+	}
+	for _, set := range h.mockSets {
+		if i := indexOf(set.mocks, m); i != -1 {
+			set.mocks = without(set.mocks, i)
+			return true
+		}
+	}
+	return false
+}
+
+// SetRecordFilter sets the filter of the ingress requests recorded as testcases. It has to be
+// called before the hooks are loaded.
+func (h *Hook) SetRecordFilter(filter *connection.Filter) {
+	h.recordFilter = filter
+}
+
+// ReuseMocks keeps the testcase mocks after they are used to respond to the outgoing calls,
+// instead of removing them.
+func (h *Hook) ReuseMocks(enable bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reuseMocks = enable
+}
+
+// RecordDepCalls enables recording the outgoing calls of the user application, along with
+// the mocks which responded to them.
+func (h *Hook) RecordDepCalls(enable bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recordDepCalls = enable
+	h.depCalls = nil
+}
+
+// AddDepCall is called by the parsers for every outgoing call in test mode, with the mock
+// used to respond to the call or nil if the call did not match any mock.
+func (h *Hook) AddDepCall(kind models.Kind, mock *models.Mock, actual string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.recordDepCalls {
+		return
+	}
+	h.depCalls = append(h.depCalls, models.DepCall{
+		Kind:   kind,
+		Mock:   mock,
+		Actual: actual,
+	})
+}
+
+// FetchDepCalls returns the outgoing calls recorded since the last fetch.
+func (h *Hook) FetchDepCalls() []models.DepCall {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	depCalls := h.depCalls
+	h.depCalls = nil
+	return depCalls
+}
+
 func (h *Hook) SetConfigMocks(m []*models.Mock) {
 	h.mu.Lock()
 	h.configMocks = m
@@ -168,9 +259,17 @@ func (h *Hook) FetchDep(indx int) *models.Mock {
 	return dep
 }
 
+// GetTcsMocks returns the mocks available to an outgoing call: the shared testcase mocks, and
+// the mocks of the testcases in flight when the mocks are isolated per testcase.
 func (h *Hook) GetTcsMocks() []*models.Mock {
 	h.mu.Lock()
 	tcsMocks := h.tcsMocks
+	if len(h.mockSets) > 0 {
+		tcsMocks = append([]*models.Mock{}, h.tcsMocks...)
+		for _, set := range h.inFlightSets() {
+			tcsMocks = append(tcsMocks, set.mocks...)
+		}
+	}
 	// fmt.Println("tcsMocks in hooks: ", tcsMocks)
 	// h.logger.Error("called GetDeps")
 	defer h.mu.Unlock()
@@ -458,6 +557,7 @@ func (h *Hook) LoadHooks(appCmd, appContainer string, pid uint32) error {
 	h.objects = objs
 
 	connectionFactory := connection.NewFactory(time.Minute, h.logger)
+	connectionFactory.SetFilter(h.recordFilter)
 	go func() {
 		// Recover from panic and gracefully shutdown
 		defer h.Recover(pkg.GenerateRandomID())
@@ -0,0 +1,71 @@
+package hooks
+
+import (
+	"sort"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// mockSet holds the mocks of a testcase in flight, along with the time its request was sent.
+type mockSet struct {
+	started time.Time
+	mocks   []*models.Mock
+}
+
+// AddMockSet makes the mocks of a testcase available while the testcase is running. The mock
+// sets isolate the mocks of the testcases running concurrently: the outgoing calls are resolved
+// against the sets of the testcases whose request windows they fall in, call by call, since the
+// pooled and kept-alive connections of the application are shared by the testcases.
+func (h *Hook) AddMockSet(id string, mocks []*models.Mock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.mockSets == nil {
+		h.mockSets = map[string]*mockSet{}
+	}
+	h.mockSets[id] = &mockSet{started: time.Now(), mocks: append([]*models.Mock{}, mocks...)}
+}
+
+// RemoveMockSet removes the mocks of the testcase once it has run, and returns the mocks which
+// were not used to respond to its outgoing calls.
+func (h *Hook) RemoveMockSet(id string) []*models.Mock {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	set, ok := h.mockSets[id]
+	if !ok {
+		return nil
+	}
+	delete(h.mockSets, id)
+	return set.mocks
+}
+
+// inFlightSets returns the mock sets of the testcases in flight, the latest started first. Like
+// during record, a call made while several testcases are running most likely belongs to the
+// testcase which started the latest.
+func (h *Hook) inFlightSets() []*mockSet {
+	sets := make([]*mockSet, 0, len(h.mockSets))
+	for _, set := range h.mockSets {
+		sets = append(sets, set)
+	}
+	sort.SliceStable(sets, func(i, j int) bool {
+		return sets[i].started.After(sets[j].started)
+	})
+	return sets
+}
+
+func indexOf(mocks []*models.Mock, m *models.Mock) int {
+	for i, mock := range mocks {
+		if mock == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// without returns a copy of the mocks without the mock at the index, since the parsers may
+// still be reading the older slice.
+func without(mocks []*models.Mock, i int) []*models.Mock {
+	res := make([]*models.Mock, 0, len(mocks)-1)
+	res = append(res, mocks[:i]...)
+	return append(res, mocks[i+1:]...)
+}
@@ -0,0 +1,107 @@
+package hooks
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestMockSets(t *testing.T) {
+	mock := func(name string) *models.Mock { return &models.Mock{Name: name} }
+	names := func(mocks []*models.Mock) []string {
+		res := []string{}
+		for _, m := range mocks {
+			res = append(res, m.Name)
+		}
+		return res
+	}
+
+	// consume is an outgoing call on a connection: it takes the first available mock of the name
+	type consume struct {
+		mock string
+		want bool
+	}
+	tests := []struct {
+		name   string
+		shared []string
+		sets   map[string][]string
+		// order is the order in which the testcases start
+		order []string
+		calls []consume
+		// available are the mocks available to the calls once they are made
+		available []string
+		// unused are the mocks left in the sets of the testcases
+		unused map[string][]string
+	}{
+		{
+			name:      "two testcases sharing one connection",
+			sets:      map[string][]string{"test-1": {"select-1"}, "test-2": {"select-2"}},
+			order:     []string{"test-1", "test-2"},
+			calls:     []consume{{mock: "select-2", want: true}, {mock: "select-1", want: true}},
+			available: []string{},
+			unused:    map[string][]string{"test-1": {}, "test-2": {}},
+		},
+		{
+			name:      "the latest started testcase comes first",
+			shared:    []string{"startup"},
+			sets:      map[string][]string{"test-1": {"a"}, "test-2": {"b"}, "test-3": {"c"}},
+			order:     []string{"test-2", "test-1", "test-3"},
+			available: []string{"startup", "c", "a", "b"},
+			unused:    map[string][]string{"test-1": {"a"}, "test-2": {"b"}, "test-3": {"c"}},
+		},
+		{
+			name:      "a mock is consumed once",
+			shared:    []string{"startup"},
+			sets:      map[string][]string{"test-1": {"a", "b"}},
+			order:     []string{"test-1"},
+			calls:     []consume{{mock: "a", want: true}, {mock: "a", want: false}, {mock: "startup", want: true}},
+			available: []string{"b"},
+			unused:    map[string][]string{"test-1": {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hook{mu: &sync.Mutex{}}
+			mocks := map[string]*models.Mock{}
+			var shared []*models.Mock
+			for _, name := range tt.shared {
+				mocks[name] = mock(name)
+				shared = append(shared, mocks[name])
+			}
+			h.SetTcsMocks(shared)
+			for _, id := range tt.order {
+				var set []*models.Mock
+				for _, name := range tt.sets[id] {
+					mocks[name] = mock(name)
+					set = append(set, mocks[name])
+				}
+				h.AddMockSet(id, set)
+				// the testcases start one after the other
+				h.mockSets[id].started = h.mockSets[id].started.Add(time.Duration(len(h.mockSets)) * time.Millisecond)
+			}
+
+			for _, call := range tt.calls {
+				if got := h.DeleteTcsMock(mocks[call.mock]); got != call.want {
+					t.Errorf("DeleteTcsMock(%s) = %v, want %v", call.mock, got, call.want)
+				}
+			}
+			if got := names(h.GetTcsMocks()); !reflect.DeepEqual(got, tt.available) {
+				t.Errorf("GetTcsMocks() = %v, want %v", got, tt.available)
+			}
+			if got := h.GetDepsSize(); got != len(tt.available) {
+				t.Errorf("GetDepsSize() = %d, want %d", got, len(tt.available))
+			}
+			for id, want := range tt.unused {
+				if got := names(h.RemoveMockSet(id)); !reflect.DeepEqual(got, want) {
+					t.Errorf("RemoveMockSet(%s) = %v, want %v", id, got, want)
+				}
+			}
+			if len(h.GetTcsMocks()) != len(h.tcsMocks) {
+				t.Errorf("GetTcsMocks() returns the mocks of the removed testcases")
+			}
+		})
+	}
+}
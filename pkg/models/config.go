@@ -0,0 +1,179 @@
+package models
+
+// Config is the project config read from keploy.yaml. It sets the defaults of the flags of the
+// commands, which are overridden by the KEPLOY_* environment variables and the flags themselves.
+type Config struct {
+	Path             string `json:"path" yaml:"path"`
+	Command          string `json:"command" yaml:"command"`
+	ContainerName    string `json:"containerName" yaml:"containerName"`
+	NetworkName      string `json:"networkName" yaml:"networkName"`
+	Delay            uint64 `json:"delay" yaml:"delay"`
+	APITimeout       uint64 `json:"apiTimeout" yaml:"apiTimeout"`
+	PassThroughPorts []uint `json:"passThroughPorts" yaml:"passThroughPorts"`
+	// Record filters the ingress requests recorded as testcases.
+	Record RecordFilter `json:"record" yaml:"record,omitempty"`
+	// Redact replaces the secrets in the recorded testcases and mocks with placeholders.
+	Redact RedactOptions `json:"redact" yaml:"redact,omitempty"`
+	// Test applies to all the test sets, below the config.yaml of the keploy directory.
+	Test TestSetConfig `json:"test" yaml:"test,omitempty"`
+	// TestSets override the config for the named test sets, below their config.yaml.
+	TestSets map[string]TestSetConfig `json:"testSets" yaml:"testSets,omitempty"`
+	// Keys are the keys set in the config file, e.g. "delay" and "record.sampleRate", to tell
+	// the zero values set in the file from the missing ones.
+	Keys map[string]bool `json:"-" yaml:"-"`
+}
+
+// RecordFilter decides which ingress http requests are recorded as testcases, e.g. to skip the
+// health checks, metrics scrapes and static assets. The paths and hosts are globs where *
+// matches within a path segment and ** across segments, e.g. "/static/**". The header values
+// are globs as well.
+type RecordFilter struct {
+	// A request is only recorded when it matches one of the values of every include list which
+	// is set, and none of the values of the exclude lists.
+	IncludePaths   []string          `json:"includePaths" yaml:"includePaths,omitempty"`
+	ExcludePaths   []string          `json:"excludePaths" yaml:"excludePaths,omitempty"`
+	IncludeMethods []string          `json:"includeMethods" yaml:"includeMethods,omitempty"`
+	ExcludeMethods []string          `json:"excludeMethods" yaml:"excludeMethods,omitempty"`
+	IncludeHosts   []string          `json:"includeHosts" yaml:"includeHosts,omitempty"`
+	ExcludeHosts   []string          `json:"excludeHosts" yaml:"excludeHosts,omitempty"`
+	IncludeHeaders map[string]string `json:"includeHeaders" yaml:"includeHeaders,omitempty"`
+	ExcludeHeaders map[string]string `json:"excludeHeaders" yaml:"excludeHeaders,omitempty"`
+	// SampleRate is the fraction of the matching requests which are recorded, e.g. 0.1. All of
+	// them are recorded when it is zero or one.
+	SampleRate float64 `json:"sampleRate" yaml:"sampleRate,omitempty"`
+	// MaxPerEndpoint is the maximum number of identical requests recorded for an endpoint. The
+	// requests are identical when their method, path, query and body are the same.
+	MaxPerEndpoint int `json:"maxPerEndpoint" yaml:"maxPerEndpoint,omitempty"`
+	// Dedupe skips the testcases whose normalised request and response are already recorded in
+	// the test set. See yaml.Signature for the normalisation.
+	Dedupe bool `json:"dedupe" yaml:"dedupe,omitempty"`
+}
+
+// RedactOptions are the rules to find the secrets in the recorded testcases and mocks, which
+// are replaced with placeholders like {{REDACTED_1a2b3c4d5e6f}} before they are written.
+type RedactOptions struct {
+	// Headers are the names of the request and response headers whose values are redacted.
+	Headers []string `json:"headers" yaml:"headers,omitempty"`
+	// Fields are the JSON body, form and query fields whose values are redacted. A field is
+	// either a dot separated path like "user.ssn", or a key matching at any depth like "password".
+	Fields []string `json:"fields" yaml:"fields,omitempty"`
+	// Patterns are the regexes of the secrets in the values. Only the first group of the
+	// regex is redacted when it has one, e.g. "api_key=(\\w+)".
+	Patterns []string `json:"patterns" yaml:"patterns,omitempty"`
+	// Detectors are the built-in rules to enable: auth, cookie, password, jwt, aws, private-key,
+	// db-url and postgres, or all of them.
+	Detectors []string `json:"detectors" yaml:"detectors,omitempty"`
+	// Salt is mixed into the placeholders, so that they can't be matched against guessed secrets.
+	Salt string `json:"salt" yaml:"salt,omitempty"`
+}
+
+// DefaultConfig returns the config with the default values of the flags.
+func DefaultConfig() *Config {
+	return &Config{
+		Delay:            5,
+		APITimeout:       5,
+		PassThroughPorts: []uint{},
+	}
+}
+
+// TestSetConfig is the configuration for running the testcases. The config.yaml in the keploy
+// directory applies to all the test sets, and the config.yaml in a test set directory
+// overrides it for the testcases of that test set.
+type TestSetConfig struct {
+	// Noise lists the noisy fields of all the testcases, along with the noise of every testcase.
+	Noise    []string         `json:"noise" yaml:"noise,omitempty"`
+	Compare  *MatchOptions    `json:"compare" yaml:"compare,omitempty"`
+	Headers  *HeaderOptions   `json:"headers" yaml:"headers,omitempty"`
+	Protobuf *ProtobufOptions `json:"protobuf" yaml:"protobuf,omitempty"`
+	Stream   *StreamOptions   `json:"stream" yaml:"stream,omitempty"`
+	Hooks    *HookOptions     `json:"hooks" yaml:"hooks,omitempty"`
+	Latency  *LatencyOptions  `json:"latency" yaml:"latency,omitempty"`
+}
+
+// MatchOptions configures how the JSON bodies of the responses are compared.
+type MatchOptions struct {
+	// StrictArrayOrder compares the arrays element by element instead of as unordered lists.
+	StrictArrayOrder bool `json:"strictArrayOrder" yaml:"strictArrayOrder,omitempty"`
+	// ArrayKey is the field used to pair the objects of unordered arrays, e.g. "id".
+	ArrayKey string `json:"arrayKey" yaml:"arrayKey,omitempty"`
+	// NumericTolerance is the maximum absolute difference for the numbers to be equal.
+	NumericTolerance float64 `json:"numericTolerance" yaml:"numericTolerance,omitempty"`
+	// CoerceTypes treats the scalars with the same text as equal, e.g. "1" and 1.
+	CoerceTypes bool `json:"coerceTypes" yaml:"coerceTypes,omitempty"`
+	// IgnoreExtraFields ignores the fields of the actual objects which are not expected.
+	IgnoreExtraFields bool `json:"ignoreExtraFields" yaml:"ignoreExtraFields,omitempty"`
+	// Paths overrides the options for the field at a path, e.g. "body.data.items", and the
+	// fields nested in it.
+	Paths map[string]MatchOptions `json:"paths" yaml:"paths,omitempty"`
+}
+
+// HeaderOptions configures how the headers of the responses are compared. The header names
+// are case insensitive globs, e.g. "X-Request-*", or regexes between slashes, e.g. "/^x-b3-/".
+type HeaderOptions struct {
+	// Ignore lists the headers which are not compared, along with the default ignore list.
+	Ignore []string `json:"ignore" yaml:"ignore,omitempty"`
+	// NoDefaults compares the headers of the default ignore list as well, unless they are in
+	// Ignore.
+	NoDefaults bool `json:"noDefaults" yaml:"noDefaults,omitempty"`
+	// Rules match the values of the headers instead of comparing them exactly.
+	Rules []HeaderRule `json:"rules" yaml:"rules,omitempty"`
+}
+
+// HeaderRule matches the values of the headers whose names match Name.
+type HeaderRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Value is the regex which the actual value of the header has to match.
+	Value string `json:"value" yaml:"value,omitempty"`
+	// Present only checks that the header is in the actual response.
+	Present bool `json:"present" yaml:"present,omitempty"`
+}
+
+// ProtobufOptions configures how the protobuf bodies of the responses are decoded to compare them.
+type ProtobufOptions struct {
+	// DescriptorSet is the path of the file descriptor set of the messages, generated by
+	// protoc --descriptor_set_out --include_imports.
+	DescriptorSet string `json:"descriptorSet" yaml:"descriptorSet"`
+	// Message is the full name of the message type of the bodies, e.g. "shop.v1.Order", used
+	// when the content type does not name it.
+	Message string `json:"message" yaml:"message,omitempty"`
+}
+
+// StreamOptions configures how the events of the streamed responses are compared.
+type StreamOptions struct {
+	// TimingTolerance is the maximum difference in milliseconds between the expected and actual
+	// delays of the events. The delays are not compared when it is zero.
+	TimingTolerance int64 `json:"timingTolerance" yaml:"timingTolerance,omitempty"`
+}
+
+// HookOptions lists the commands run around the test set and around every testcase, e.g. to
+// seed a database, reset a cache or start a sidecar. The commands are run by sh with the
+// KEPLOY_TEST_SET and KEPLOY_TEST_CASE environment variables.
+type HookOptions struct {
+	// Pre runs before the application is launched for the test set, and Post after its testcases.
+	Pre  []HookCommand `json:"pre" yaml:"pre,omitempty"`
+	Post []HookCommand `json:"post" yaml:"post,omitempty"`
+	// BeforeEach runs before every attempt of a testcase, and AfterEach after it.
+	BeforeEach []HookCommand `json:"beforeEach" yaml:"beforeEach,omitempty"`
+	AfterEach  []HookCommand `json:"afterEach" yaml:"afterEach,omitempty"`
+}
+
+// HookCommand is a command run as a hook.
+type HookCommand struct {
+	Command string `json:"command" yaml:"command"`
+	// Timeout is the number of seconds after which the command is killed, 60 when zero.
+	Timeout uint64 `json:"timeout" yaml:"timeout,omitempty"`
+}
+
+// LatencyOptions configures how the latencies of the http responses are compared to the
+// recorded latencies. The latencies are not compared when none of the limits is set.
+type LatencyOptions struct {
+	// MaxRatio is the maximum ratio of the actual latency to the recorded latency, e.g. 2.
+	MaxRatio float64 `json:"maxRatio" yaml:"maxRatio,omitempty"`
+	// MinMs is the latency in milliseconds under which MaxRatio is not checked, since the
+	// ratios of small latencies are noisy.
+	MinMs int64 `json:"minMs" yaml:"minMs,omitempty"`
+	// BudgetMs is the maximum actual latency in milliseconds.
+	BudgetMs int64 `json:"budgetMs" yaml:"budgetMs,omitempty"`
+	// WarnOnly logs the slow responses as warnings instead of failing the testcases.
+	WarnOnly bool `json:"warnOnly" yaml:"warnOnly,omitempty"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Method string
 
 type HttpReq struct {
@@ -13,12 +15,18 @@ type HttpReq struct {
 	BodyType   string            `json:"body_type" yaml:"body_type"`
 	Binary     string            `json:"binary" yaml:"binary,omitempty"`
 	Form       []FormData        `json:"form" yaml:"form,omitempty"`
+	Timestamp  time.Time         `json:"timestamp" yaml:"timestamp,omitempty"`
 }
 
 type FormData struct {
 	Key    string   `json:"key" bson:"key" yaml:"key"`
 	Values []string `json:"values" bson:"values,omitempty" yaml:"values,omitempty"`
-	Paths  []string `json:"paths" bson:"paths,omitempty" yaml:"paths,omitempty"`
+	// Paths are the paths of the uploaded files, relative to the directory of the testcase.
+	Paths []string `json:"paths" bson:"paths,omitempty" yaml:"paths,omitempty"`
+	// Header is the header of the parts, which is sent as it was recorded.
+	Header map[string]string `json:"header" bson:"header,omitempty" yaml:"header,omitempty"`
+	// Contents are the contents of the files at Paths, which are stored next to the testcase.
+	Contents [][]byte `json:"-" bson:"-" yaml:"-"`
 }
 
 type HttpResp struct {
@@ -30,4 +38,17 @@ type HttpResp struct {
 	ProtoMajor    int               `json:"proto_major" yaml:"proto_major"`
 	ProtoMinor    int               `json:"proto_minor" yaml:"proto_minor"`
 	Binary        string            `json:"binary" yaml:"binary,omitempty"`
+	Timestamp     time.Time         `json:"timestamp" yaml:"timestamp,omitempty"`
+	// Latency is the number of milliseconds taken by the app from the request to the response.
+	Latency int64 `json:"latency" yaml:"latency,omitempty"`
+	// StreamEvents are the events of a Server-Sent Events response, or the chunks of a
+	// streamed chunked response, in their order. The body of a streamed response is empty.
+	StreamEvents []HttpStreamEvent `json:"stream_events" yaml:"stream_events,omitempty"`
+}
+
+// HttpStreamEvent is an event of a Server-Sent Events response, or a chunk of a chunked response.
+type HttpStreamEvent struct {
+	Data string `json:"data" yaml:"data"`
+	// Delay is the number of milliseconds since the start of the response.
+	Delay int64 `json:"delay" yaml:"delay"`
 }
@@ -0,0 +1,48 @@
+package models
+
+// LoadReport is the result of replaying the testcases of a test set as load. It is written
+// alongside the test report of the test run.
+type LoadReport struct {
+	Version Version `json:"version" yaml:"version"`
+	Name    string  `json:"name" yaml:"name"`
+	TestSet string  `json:"testSet" yaml:"test_set"`
+	// RPS is the target number of requests per second, and Duration is the number of seconds
+	// for which the requests are sent.
+	RPS         uint   `json:"rps" yaml:"rps"`
+	Duration    uint64 `json:"duration" yaml:"duration"`
+	Concurrency uint   `json:"concurrency" yaml:"concurrency"`
+	// Sent is the number of requests sent, and Dropped is the number of requests which were not
+	// sent since Concurrency requests were already in flight.
+	Sent      int          `json:"sent" yaml:"sent"`
+	Dropped   int          `json:"dropped" yaml:"dropped,omitempty"`
+	Total     LoadStats    `json:"total" yaml:"total"`
+	Endpoints []LoadResult `json:"endpoints" yaml:"endpoints"`
+}
+
+// LoadResult is the result of the requests sent to an endpoint, e.g. "GET /users".
+type LoadResult struct {
+	Endpoint  string `json:"endpoint" yaml:"endpoint"`
+	LoadStats `json:",inline" yaml:",inline"`
+}
+
+// LoadStats summarises the responses to the requests of a load run.
+type LoadStats struct {
+	Requests int `json:"requests" yaml:"requests"`
+	// Errors is the number of requests which failed without a response or with a 5xx status.
+	Errors int `json:"errors" yaml:"errors"`
+	// Mismatches is the number of responses whose status code differs from the recorded one.
+	Mismatches int     `json:"mismatches" yaml:"mismatches"`
+	ErrorRate  float64 `json:"errorRate" yaml:"error_rate"`
+	// StatusCodes is the number of responses with every status code.
+	StatusCodes map[int]int  `json:"statusCodes" yaml:"status_codes,omitempty"`
+	Latency     LatencyStats `json:"latency" yaml:"latency"`
+}
+
+// LatencyStats are the percentiles of the latencies of the responses, in milliseconds.
+type LatencyStats struct {
+	P50  float64 `json:"p50" yaml:"p50"`
+	P95  float64 `json:"p95" yaml:"p95"`
+	P99  float64 `json:"p99" yaml:"p99"`
+	Max  float64 `json:"max" yaml:"max"`
+	Mean float64 `json:"mean" yaml:"mean"`
+}
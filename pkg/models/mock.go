@@ -1,5 +1,11 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Mock struct {
 	Version Version  `json:"Version,omitempty"`
 	Name    string   `json:"Name,omitempty"`
@@ -34,6 +40,58 @@ type MockSpec struct {
 	//for grpc
 	GRPCReq  *GrpcReq  `json:"gRPCRequest,omitempty"`
 	GRPCResp *GrpcResp `json:"grpcResponse,omitempty"`
+
+	// Timestamp is the time at which the mock was captured. It is used to find the
+	// testcase during which the outgoing call was made.
+	Timestamp time.Time `json:"Timestamp,omitempty"`
+}
+
+// RequestString renders the recorded request of the mock as text, to report the outgoing
+// calls that the mock was expected to respond to. The request packets of the generic and
+// postgres mocks are rendered as base64, one packet per line.
+func (m *Mock) RequestString() string {
+	switch m.Kind {
+	case HTTP:
+		if m.Spec.HttpReq == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s %s\n%s", m.Spec.HttpReq.Method, m.Spec.HttpReq.URL, m.Spec.HttpReq.Body)
+	case Mongo:
+		lines := []string{}
+		for _, req := range m.Spec.MongoRequests {
+			switch msg := req.Message.(type) {
+			case *MongoOpMessage:
+				lines = append(lines, msg.Sections...)
+			case *MongoOpQuery:
+				lines = append(lines, msg.Query)
+			default:
+				lines = append(lines, fmt.Sprint(req.Message))
+			}
+		}
+		return strings.Join(lines, "\n")
+	case GRPC_EXPORT:
+		if m.Spec.GRPCReq == nil {
+			return ""
+		}
+		return m.Spec.GRPCReq.Headers.PseudoHeaders[":path"] + "\n" + m.Spec.GRPCReq.Body.DecodedData
+	default:
+		lines := []string{}
+		for _, req := range m.Spec.GenericRequests {
+			for _, msg := range req.Message {
+				lines = append(lines, msg.Data)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+}
+
+// DepCall is an outgoing call made by the user application during a test run, along with
+// the mock which was used to respond to it. Mock is nil if the call did not match any mock.
+type DepCall struct {
+	Kind Kind
+	Mock *Mock
+	// Actual is the request of the outgoing call
+	Actual string
 }
 
 // OutputBinary store the encoded binary output of the egress calls as base64-encoded strings
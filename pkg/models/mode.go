@@ -16,6 +16,9 @@ const (
 	MODE_OFF    Mode     = "off"
 	KCTX        KctxType = "KeployContext"
 	KTime       KctxType = "KeployTime"
+	// KTimeHeader carries the time at which the testcase was recorded, in RFC 3339 format, so
+	// that the SDKs present it to the application as the current time during the test run.
+	KTimeHeader = "KEPLOY_TEST_TIME"
 )
 
 var (
@@ -37,6 +37,8 @@ type TestCase struct {
 	GrpcReq  GrpcReq             `json:"grpcReq"`
 	Anchors  map[string][]string `json:"anchors"`
 	Noise    []string            `json:"noise"`
+	Compare  *MatchOptions       `json:"compare"`
+	Extract  map[string]string   `json:"extract"`
 	Mocks    []*Mock             `json:"mocks"`
 	Type     string              `json:"type"`
 }
@@ -3,11 +3,19 @@ package models
 type TestReport struct {
 	Version Version      `json:"version" yaml:"version"`
 	Name    string       `json:"name" yaml:"name"`
+	TestSet string       `json:"testSet" yaml:"test_set"`
 	Status  string       `json:"status" yaml:"status"`
 	Success int          `json:"success" yaml:"success"`
 	Failure int          `json:"failure" yaml:"failure"`
 	Total   int          `json:"total" yaml:"total"`
 	Tests   []TestResult `json:"tests" yaml:"tests,omitempty"`
+	// Quarantined is the number of failed flaky testcases which don't fail the test run
+	Quarantined int `json:"quarantined" yaml:"quarantined,omitempty"`
+	// Hooks are the results of the pre and post hooks of the test set
+	Hooks []HookResult `json:"hooks" yaml:"hooks,omitempty"`
+	// Sequential lists the testcases run sequentially in a parallel run, since their mocks
+	// can't be isolated
+	Sequential []string `json:"sequential" yaml:"sequential,omitempty"`
 }
 
 type TestResult struct {
@@ -16,14 +24,41 @@ type TestResult struct {
 	Status       TestStatus   `json:"status" yaml:"status"`
 	Started      int64        `json:"started" yaml:"started"`
 	Completed    int64        `json:"completed" yaml:"completed"`
+	// Duration is the number of milliseconds taken by the testcase, since the start and
+	// completion times are in seconds.
+	Duration     int64        `json:"duration" yaml:"duration,omitempty"`
 	TestCasePath string       `json:"testCasePath" yaml:"test_case_path"`
 	MockPath     string       `json:"mockPath" yaml:"mock_path"`
 	TestCaseID   string       `json:"testCaseID" yaml:"test_case_id"`
 	Req          HttpReq  `json:"req" yaml:"req,omitempty"`
 	// Mocks        []string     `json:"mocks" yaml:"mocks"`
 	Res          HttpResp `json:"resp" yaml:"resp,omitempty"`
+	GrpcReq      GrpcReq      `json:"grpcReq" yaml:"grpc_req,omitempty"`
+	GrpcRes      GrpcResp     `json:"grpcResp" yaml:"grpc_resp,omitempty"`
 	Noise        []string     `json:"noise" yaml:"noise,omitempty"`
 	Result       Result       `json:"result" yaml:"result"`
+	Attempts     int          `json:"attempts" yaml:"attempts,omitempty"`
+	Flaky        bool         `json:"flaky" yaml:"flaky,omitempty"`
+	Quarantined  bool         `json:"quarantined" yaml:"quarantined,omitempty"`
+	Hooks        []HookResult `json:"hooks" yaml:"hooks,omitempty"`
+}
+
+// HookResult is the result of a hook command run around a test set or a testcase.
+type HookResult struct {
+	// Stage is one of pre, post, beforeEach and afterEach.
+	Stage    string     `json:"stage" yaml:"stage"`
+	Command  string     `json:"command" yaml:"command"`
+	Status   TestStatus `json:"status" yaml:"status"`
+	Output   string     `json:"output" yaml:"output,omitempty"`
+	Error    string     `json:"error" yaml:"error,omitempty"`
+	// Duration is the number of milliseconds taken by the command.
+	Duration int64 `json:"duration" yaml:"duration"`
+}
+
+// TestHistory stores the statuses of the testcases of every test set in the recent test runs,
+// to find the flaky testcases.
+type TestHistory struct {
+	TestSets map[string]map[string][]TestStatus `json:"testSets" yaml:"test_sets"`
 }
 
 type TestRunStatus string
@@ -38,7 +73,18 @@ type Result struct {
 	StatusCode    IntResult      `json:"status_code" bson:"status_code" yaml:"status_code"`
 	HeadersResult []HeaderResult `json:"headers_result" bson:"headers_result" yaml:"headers_result"`
 	BodyResult    []BodyResult   `json:"body_result" bson:"body_result" yaml:"body_result"`
+	TrailerResult []HeaderResult `json:"trailer_result,omitempty" bson:"trailer_result,omitempty" yaml:"trailer_result,omitempty"`
 	DepResult     []DepResult    `json:"dep_result" bson:"dep_result" yaml:"dep_result"`
+	Latency       *LatencyResult `json:"latency,omitempty" bson:"latency,omitempty" yaml:"latency,omitempty"`
+}
+
+// LatencyResult compares the latency of the response with the recorded latency, in milliseconds.
+type LatencyResult struct {
+	Normal   bool  `json:"normal" bson:"normal" yaml:"normal"`
+	Expected int64 `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   int64 `json:"actual" bson:"actual" yaml:"actual"`
+	// Limit is the maximum latency allowed by the latency options.
+	Limit int64 `json:"limit" bson:"limit" yaml:"limit"`
 }
 
 type DepResult struct {
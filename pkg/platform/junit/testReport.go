@@ -0,0 +1,200 @@
+package junit
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+// TestReport writes the test reports in the JUnit XML format, which is read by most of
+// the CI servers. The keploy YAML report is still written alongside, since it is the
+// one read back by keploy.
+type TestReport struct {
+	*yaml.TestReport
+}
+
+func NewTestReportFS(logger *zap.Logger) *TestReport {
+	return &TestReport{
+		TestReport: yaml.NewTestReportFS(logger),
+	}
+}
+
+type testSuites struct {
+	XMLName xml.Name    `xml:"testsuites"`
+	Suites  []testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      string     `xml:"time,attr"`
+	Testcases []testCase `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+	Skipped   *skipped `xml:"skipped,omitempty"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+type failure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func (fe *TestReport) Write(ctx context.Context, path string, doc *models.TestReport) error {
+	// the yaml report names the test report
+	err := fe.TestReport.Write(ctx, path, doc)
+	if err != nil {
+		return err
+	}
+	// the report is written at the start of the test run as well
+	if doc.Status == string(models.TestRunStatusRunning) {
+		return nil
+	}
+
+	suite := testSuite{
+		Name:  doc.TestSet,
+		Tests: len(doc.Tests),
+	}
+	if suite.Name == "" {
+		suite.Name = doc.Name
+	}
+	var total int64
+	for _, test := range doc.Tests {
+		total += test.Duration
+		tc := testCase{
+			Name:      test.TestCaseID,
+			Classname: suite.Name,
+			Time:      seconds(test.Duration),
+		}
+		switch {
+		case test.Status == models.TestStatusPassed:
+		case test.Quarantined:
+			// the quarantined testcases don't fail the test run
+			suite.Skipped++
+			tc.Skipped = &skipped{Message: "the flaky testcase failed and is quarantined"}
+		case test.Status == models.TestStatusFailed:
+			suite.Failures++
+			message, contents := failureMessage(test.Result, test.Hooks)
+			tc.Failure = &failure{
+				Message:  message,
+				Type:     string(test.Status),
+				Contents: contents,
+			}
+		default:
+			suite.Skipped++
+			tc.Skipped = &skipped{}
+		}
+		suite.Testcases = append(suite.Testcases, tc)
+	}
+	// a failed pre hook stops the test set before its testcases are run, so the failed hooks
+	// of the test set are reported as testcases of their own
+	for _, hook := range doc.Hooks {
+		total += hook.Duration
+		if hook.Status != models.TestStatusFailed {
+			continue
+		}
+		suite.Tests++
+		suite.Failures++
+		suite.Testcases = append(suite.Testcases, testCase{
+			Name:      hook.Stage + " hook",
+			Classname: suite.Name,
+			Time:      seconds(hook.Duration),
+			Failure: &failure{
+				Message:  fmt.Sprintf("the %s hook of the test set failed", hook.Stage),
+				Type:     string(hook.Status),
+				Contents: fmt.Sprintf("command: %s\nerror: %s\noutput: %s\n", hook.Command, hook.Error, hook.Output),
+			},
+		})
+	}
+	suite.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(testSuites{Suites: []testSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to marshal the test report to junit xml. error: %s", err.Error())
+	}
+	data = append([]byte(xml.Header), data...)
+
+	err = os.WriteFile(filepath.Join(path, doc.Name+".xml"), data, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to write test report in junit xml file. error: %s", err.Error())
+	}
+	return nil
+}
+
+// seconds formats the milliseconds as the fractional seconds of the junit report.
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// failureMessage summarises the mismatches and the failed hooks of a failed testcase in one
+// line, and lists the expected and actual values of every mismatch for the contents of the failure.
+func failureMessage(res models.Result, hooks []models.HookResult) (string, string) {
+	var (
+		summary  []string
+		contents strings.Builder
+	)
+	if res.StatusCode.Expected != 0 && !res.StatusCode.Normal {
+		summary = append(summary, "status code")
+		fmt.Fprintf(&contents, "status code:\n  expected: %d\n  actual: %d\n", res.StatusCode.Expected, res.StatusCode.Actual)
+	}
+	if res.Latency != nil && !res.Latency.Normal {
+		summary = append(summary, "latency")
+		fmt.Fprintf(&contents, "latency:\n  expected: %dms (limit %dms)\n  actual: %dms\n", res.Latency.Expected, res.Latency.Limit, res.Latency.Actual)
+	}
+	for _, h := range res.HeadersResult {
+		if !h.Normal {
+			summary = append(summary, "header "+h.Expected.Key)
+			fmt.Fprintf(&contents, "header %s:\n  expected: %v\n  actual: %v\n", h.Expected.Key, h.Expected.Value, h.Actual.Value)
+		}
+	}
+	for _, h := range res.TrailerResult {
+		if !h.Normal {
+			summary = append(summary, "trailer "+h.Expected.Key)
+			fmt.Fprintf(&contents, "trailer %s:\n  expected: %v\n  actual: %v\n", h.Expected.Key, h.Expected.Value, h.Actual.Value)
+		}
+	}
+	for _, b := range res.BodyResult {
+		if !b.Normal {
+			summary = append(summary, "body")
+			fmt.Fprintf(&contents, "body:\n  expected: %s\n  actual: %s\n", b.Expected, b.Actual)
+		}
+	}
+	for _, dep := range res.DepResult {
+		for _, meta := range dep.Meta {
+			if !meta.Normal {
+				summary = append(summary, "dependency "+dep.Type)
+				fmt.Fprintf(&contents, "dependency %s %s:\n  expected: %s\n  actual: %s\n", dep.Type, meta.Key, meta.Expected, meta.Actual)
+			}
+		}
+	}
+	for _, hook := range hooks {
+		if hook.Status == models.TestStatusFailed {
+			summary = append(summary, hook.Stage+" hook")
+			fmt.Fprintf(&contents, "%s hook %s:\n  error: %s\n  output: %s\n", hook.Stage, hook.Command, hook.Error, hook.Output)
+		}
+	}
+	if len(summary) == 0 {
+		return "testcase failed", contents.String()
+	}
+	return "mismatch in " + strings.Join(summary, ", "), contents.String()
+}
@@ -0,0 +1,84 @@
+package junit
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// readSuite writes the test report and reads back the test suite of its junit xml file.
+func readSuite(t *testing.T, doc *models.TestReport) testSuite {
+	dir := t.TempDir()
+	if err := NewTestReportFS(zap.NewNop()).Write(context.Background(), dir, doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, doc.Name+".xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites testSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatal(err)
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("junit report has %d suites, want 1", len(suites.Suites))
+	}
+	return suites.Suites[0]
+}
+
+func TestWriteQuarantined(t *testing.T) {
+	doc := &models.TestReport{
+		Name:        "report-1",
+		TestSet:     "test-set-0",
+		Status:      string(models.TestRunStatusPassed),
+		Success:     1,
+		Failure:     1,
+		Quarantined: 1,
+		Tests: []models.TestResult{
+			{TestCaseID: "test-1", Status: models.TestStatusPassed, Duration: 42},
+			{TestCaseID: "test-2", Status: models.TestStatusFailed, Flaky: true, Quarantined: true},
+			{TestCaseID: "test-3", Status: models.TestStatusFailed},
+		},
+	}
+	suite := readSuite(t, doc)
+
+	if suite.Tests != 3 || suite.Failures != doc.Failure || suite.Skipped != doc.Quarantined {
+		t.Errorf("suite counts = %d tests, %d failures and %d skipped, want 3, %d and %d", suite.Tests, suite.Failures, suite.Skipped, doc.Failure, doc.Quarantined)
+	}
+	if suite.Testcases[0].Time != "0.042" || suite.Time != "0.042" {
+		t.Errorf("times = %q for the testcase and %q for the suite, want %q", suite.Testcases[0].Time, suite.Time, "0.042")
+	}
+	quarantined := suite.Testcases[1]
+	if quarantined.Failure != nil || quarantined.Skipped == nil || quarantined.Skipped.Message == "" {
+		t.Errorf("quarantined testcase = %+v, want skipped with a message", quarantined)
+	}
+	if suite.Testcases[2].Failure == nil {
+		t.Errorf("failed testcase = %+v, want a failure", suite.Testcases[2])
+	}
+}
+
+func TestWriteHooks(t *testing.T) {
+	doc := &models.TestReport{
+		Name:    "report-1",
+		TestSet: "test-set-0",
+		Status:  string(models.TestRunStatusFailed),
+		Hooks: []models.HookResult{
+			{Stage: "pre", Command: "make seed", Status: models.TestStatusFailed, Error: "exit status 2", Duration: 1500},
+			{Stage: "post", Command: "make clean", Status: models.TestStatusPassed},
+		},
+	}
+	suite := readSuite(t, doc)
+
+	if suite.Tests != 1 || suite.Failures != 1 {
+		t.Fatalf("suite counts = %d tests and %d failures, want 1 and 1", suite.Tests, suite.Failures)
+	}
+	hook := suite.Testcases[0]
+	if hook.Name != "pre hook" || hook.Time != "1.500" || hook.Failure == nil {
+		t.Errorf("hook testcase = %+v, want the failed pre hook", hook)
+	}
+}
@@ -0,0 +1,71 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/pkg/models"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// ReadTestSetConfig reads the config.yaml of the keploy directory or of a test set directory.
+// An empty config is returned if the directory does not have a config.yaml.
+func ReadTestSetConfig(path string) (*models.TestSetConfig, error) {
+	cfg := &models.TestSetConfig{}
+	data, err := os.ReadFile(filepath.Join(path, "config.yaml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yamlLib.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf(Emoji+"failed to decode the config.yaml in %s. error: %v", path, err.Error())
+	}
+	return cfg, nil
+}
+
+// ReadConfig reads the project config from the keploy.yaml at the path. The fields which are
+// not in the file keep their default values.
+func ReadConfig(path string) (*models.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(Emoji+"failed to read the config file %s. error: %w", path, err)
+	}
+	cfg := models.DefaultConfig()
+	err = yamlLib.Unmarshal(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf(Emoji+"failed to decode the config file %s. error: %v", path, err.Error())
+	}
+	var keys map[string]interface{}
+	err = yamlLib.Unmarshal(data, &keys)
+	if err != nil {
+		return nil, fmt.Errorf(Emoji+"failed to decode the config file %s. error: %v", path, err.Error())
+	}
+	cfg.Keys = map[string]bool{}
+	for key, value := range keys {
+		cfg.Keys[key] = true
+		if section, ok := value.(map[string]interface{}); ok {
+			for subKey := range section {
+				cfg.Keys[key+"."+subKey] = true
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// WriteConfig writes the project config to the keploy.yaml at the path.
+func WriteConfig(path string, cfg *models.Config) error {
+	data, err := yamlLib.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to marshal the config. error: %v", err.Error())
+	}
+	err = os.WriteFile(path, data, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to write the config file %s. error: %v", path, err.Error())
+	}
+	return nil
+}
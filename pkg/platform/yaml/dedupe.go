@@ -0,0 +1,291 @@
+package yaml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Signature returns the signature of the testcase, which is equal for the testcases with the
+// same normalised request and response. The http requests are normalised by their method, path
+// template, query keys, the shape of their JSON body and their form fields, and the responses by
+// their status code, the shape of their JSON body and of their streamed events. The bodies which
+// are not JSON, including the binary bodies and the uploaded files, are compared by their content.
+func Signature(tc *models.TestCase) string {
+	switch tc.Kind {
+	case models.HTTP:
+		path, query := tc.HttpReq.URL, []string{}
+		if u, err := url.Parse(tc.HttpReq.URL); err == nil {
+			path = u.Path
+			for key := range u.Query() {
+				query = append(query, key)
+			}
+			sort.Strings(query)
+		}
+		return strings.Join([]string{
+			string(tc.HttpReq.Method),
+			pkg.PathTemplate(path),
+			strings.Join(query, "&"),
+			bodyShape(tc.HttpReq.Body),
+			hash(tc.HttpReq.Binary),
+			formShape(tc.HttpReq.Form),
+			fmt.Sprint(tc.HttpResp.StatusCode),
+			bodyShape(tc.HttpResp.Body),
+			hash(tc.HttpResp.Binary),
+			streamShape(tc.HttpResp.StreamEvents),
+		}, " ")
+	case models.GRPC_EXPORT:
+		return strings.Join([]string{
+			"gRPC",
+			tc.GrpcReq.Headers.PseudoHeaders[":path"],
+			bodyShape(tc.GrpcReq.Body.DecodedData),
+			tc.GrpcResp.Trailers.OrdinaryHeaders["grpc-status"],
+			bodyShape(tc.GrpcResp.Body.DecodedData),
+		}, " ")
+	}
+	return ""
+}
+
+// bodyShape returns the shape of the JSON body, e.g. {"id":number,"tags":[string]}, or the hash
+// of the body when it is not JSON.
+func bodyShape(body string) string {
+	if body == "" {
+		return "-"
+	}
+	var v interface{}
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return hash(body)
+	}
+	return jsonShape(v)
+}
+
+// hash returns the hash of the content, or - when it is empty.
+func hash(content string) string {
+	if content == "" {
+		return "-"
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// formShape returns the shape of the fields of the multipart form, e.g.
+// {"avatar":[file:a.png:<hash>],"meta":[{"id":number}]}. The values and the uploaded files are
+// shaped like the bodies, and the files are named by the base name of their path.
+func formShape(form []models.FormData) string {
+	if len(form) == 0 {
+		return "-"
+	}
+	fields := make([]string, len(form))
+	for i, field := range form {
+		var parts []string
+		for _, value := range field.Values {
+			parts = append(parts, bodyShape(value))
+		}
+		for j, path := range field.Paths {
+			content := ""
+			if j < len(field.Contents) {
+				content = string(field.Contents[j])
+			}
+			parts = append(parts, "file:"+filepath.Base(path)+":"+bodyShape(content))
+		}
+		fields[i] = fmt.Sprintf("%q:[%s]", field.Key, strings.Join(parts, ","))
+	}
+	sort.Strings(fields)
+	return "{" + strings.Join(fields, ",") + "}"
+}
+
+// streamShape returns the shapes of the data of the streamed events, which are equal for the
+// streams of the same event shapes irrespective of their number, like the JSON arrays.
+func streamShape(events []models.HttpStreamEvent) string {
+	if len(events) == 0 {
+		return "-"
+	}
+	shapes := map[string]bool{}
+	for _, event := range events {
+		shapes[bodyShape(event.Data)] = true
+	}
+	elems := make([]string, 0, len(shapes))
+	for shape := range shapes {
+		elems = append(elems, shape)
+	}
+	sort.Strings(elems)
+	return "stream[" + strings.Join(elems, "|") + "]"
+}
+
+func jsonShape(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		// the arrays of the same element shapes are equal, irrespective of their lengths
+		shapes := map[string]bool{}
+		for _, e := range v {
+			shapes[jsonShape(e)] = true
+		}
+		elems := make([]string, 0, len(shapes))
+		for shape := range shapes {
+			elems = append(elems, shape)
+		}
+		sort.Strings(elems)
+		return "[" + strings.Join(elems, "|") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = fmt.Sprintf("%q:%s", key, jsonShape(v[key]))
+		}
+		return "{" + strings.Join(fields, ",") + "}"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// duplicate returns the name of the recorded testcase of the test set with the same signature as
+// the testcase. The signatures of the recorded testcases are read on the first call.
+func (ys *Yaml) duplicate(tc *models.TestCase) (string, error) {
+	if ys.signatures == nil {
+		tcs, err := ys.ReadTestcase(ys.TcsPath, nil)
+		if err != nil {
+			return "", err
+		}
+		ys.signatures = map[string]string{}
+		for _, recorded := range tcs {
+			if _, ok := ys.signatures[Signature(recorded)]; !ok {
+				ys.signatures[Signature(recorded)] = recorded.Name
+			}
+		}
+	}
+	return ys.signatures[Signature(tc)], nil
+}
+
+// orphanMocks reports the mocks which were captured during the removed testcases. Like during
+// test, a mock captured during testcases recorded concurrently belongs to the testcase which
+// started the latest, so the mocks of the kept testcases are never reported. The config mocks
+// and the mocks without timestamps are shared by every testcase, and aren't reported either.
+func orphanMocks(mocks []*models.Mock, removed, kept []*models.TestCase) []bool {
+	type window struct {
+		start, end time.Time
+		removed    bool
+	}
+	var windows []window
+	for i, tc := range append(append([]*models.TestCase{}, removed...), kept...) {
+		if tc.Kind != models.HTTP || tc.HttpReq.Timestamp.IsZero() || tc.HttpResp.Timestamp.IsZero() {
+			continue
+		}
+		windows = append(windows, window{start: tc.HttpReq.Timestamp, end: tc.HttpResp.Timestamp, removed: i < len(removed)})
+	}
+
+	orphans := make([]bool, len(mocks))
+	for i, m := range mocks {
+		if m.Spec.Metadata["type"] == "config" || m.Spec.Timestamp.IsZero() {
+			continue
+		}
+		owner := -1
+		for j, w := range windows {
+			if m.Spec.Timestamp.Before(w.start) || m.Spec.Timestamp.After(w.end) {
+				continue
+			}
+			if owner == -1 || w.start.After(windows[owner].start) {
+				owner = j
+			}
+		}
+		orphans[i] = owner != -1 && windows[owner].removed
+	}
+	return orphans
+}
+
+// PruneMocks finds the mocks of the mocks.yaml of the test set which were captured during the
+// removed testcases, and removes them when apply is set. It returns the number of such mocks.
+func PruneMocks(path string, removed, kept []*models.TestCase, apply bool, logger *zap.Logger) (int, error) {
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	if _, err := os.Stat(filepath.Join(path, "mocks.yaml")); err != nil {
+		return 0, nil
+	}
+	docs, err := read(path, "mocks")
+	if err != nil {
+		logger.Error("failed to read the mocks from yaml", zap.Error(err), zap.Any("path", path))
+		return 0, err
+	}
+	mocks, err := decodeMocks(docs, logger)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		orphans = orphanMocks(mocks, removed, kept)
+		rest    []*NetworkTrafficDoc
+	)
+	for i, doc := range docs {
+		if !orphans[i] {
+			rest = append(rest, doc)
+		}
+	}
+	count := len(docs) - len(rest)
+	if count == 0 || !apply {
+		return count, nil
+	}
+	if err := writeMocks(path, rest, logger); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PruneSkippedMocks removes the mocks captured during the duplicate testcases skipped while
+// recording. It is called once the recording has stopped, so that the mocks written after the
+// testcases were skipped are removed as well.
+func (ys *Yaml) PruneSkippedMocks() error {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+	if len(ys.skipped) == 0 {
+		return nil
+	}
+	kept, err := ys.ReadTestcase(ys.TcsPath, nil)
+	if err != nil {
+		return err
+	}
+	n, err := PruneMocks(ys.MockPath, ys.skipped, kept, true, ys.Logger)
+	if err != nil {
+		return err
+	}
+	ys.Logger.Info("removed the mocks of the skipped duplicate testcases", zap.Any("skipped testcases", len(ys.skipped)), zap.Any("removed mocks", n))
+	ys.skipped = nil
+	return nil
+}
+
+// DeleteTestcase removes the yaml file of the testcase along with its uploaded files.
+func DeleteTestcase(tcsPath, name string, logger *zap.Logger) error {
+	err := os.Remove(filepath.Join(tcsPath, name+".yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Error("failed to remove the yaml file of the testcase", zap.Error(err), zap.Any("testcase name", name))
+		return err
+	}
+	err = os.RemoveAll(filepath.Join(tcsPath, name+"-files"))
+	if err != nil {
+		logger.Error("failed to remove the uploaded files of the testcase", zap.Error(err), zap.Any("testcase name", name))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+func TestBodyShape(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "empty bodies", a: "", b: "", same: true},
+		{name: "same fields with other values", a: `{"id":1,"name":"a"}`, b: `{"name":"b","id":2}`, same: true},
+		{name: "other field types", a: `{"id":1}`, b: `{"id":"1"}`, same: false},
+		{name: "extra field", a: `{"id":1}`, b: `{"id":1,"name":"a"}`, same: false},
+		{name: "nested objects", a: `{"user":{"id":1}}`, b: `{"user":{"id":2}}`, same: true},
+		{name: "arrays of other lengths", a: `[1,2,3]`, b: `[4]`, same: true},
+		{name: "arrays of other element types", a: `[1,2]`, b: `[1,"2"]`, same: false},
+		{name: "empty and filled arrays", a: `{"tags":[]}`, b: `{"tags":["a"]}`, same: false},
+		{name: "same text", a: "hello", b: "hello", same: true},
+		{name: "other text", a: "hello", b: "world", same: false},
+		{name: "empty and filled body", a: "", b: "{}", same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := bodyShape(tt.a), bodyShape(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("bodyShape(%q) = %s, bodyShape(%q) = %s, want same %v", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	base := func() *models.TestCase {
+		return &models.TestCase{
+			Kind: models.HTTP,
+			HttpReq: models.HttpReq{
+				Method: models.Method("POST"),
+				URL:    "http://localhost:8080/users/42?page=1&sort=name",
+				Body:   `{"name":"a"}`,
+			},
+			HttpResp: models.HttpResp{StatusCode: 200, Body: `{"id":42}`},
+		}
+	}
+	form := func(fields ...models.FormData) func(tc *models.TestCase) {
+		return func(tc *models.TestCase) {
+			tc.HttpReq.Body = ""
+			tc.HttpReq.Form = fields
+		}
+	}
+
+	tests := []struct {
+		name   string
+		a, b   func(tc *models.TestCase)
+		same   bool
+		emptyA bool
+	}{
+		{
+			name: "other ids, query values and body values",
+			b: func(tc *models.TestCase) {
+				tc.HttpReq.URL = "http://localhost:8080/users/7?sort=id&page=2"
+				tc.HttpReq.Body = `{"name":"b"}`
+				tc.HttpResp.Body = `{"id":7}`
+			},
+			same: true,
+		},
+		{name: "other method", b: func(tc *models.TestCase) { tc.HttpReq.Method = models.Method("PUT") }},
+		{name: "other query keys", b: func(tc *models.TestCase) { tc.HttpReq.URL = "http://localhost:8080/users/42?page=1" }},
+		{name: "other status code", b: func(tc *models.TestCase) { tc.HttpResp.StatusCode = 404 }},
+		{name: "other response shape", b: func(tc *models.TestCase) { tc.HttpResp.Body = `{"id":"42"}` }},
+		{
+			name: "same binary bodies",
+			a:    func(tc *models.TestCase) { tc.HttpReq.Binary, tc.HttpResp.Binary = "AAEC", "AwQF" },
+			b:    func(tc *models.TestCase) { tc.HttpReq.Binary, tc.HttpResp.Binary = "AAEC", "AwQF" },
+			same: true,
+		},
+		{
+			name: "other binary requests",
+			a:    func(tc *models.TestCase) { tc.HttpReq.Binary = "AAEC" },
+			b:    func(tc *models.TestCase) { tc.HttpReq.Binary = "AAED" },
+		},
+		{
+			name: "other binary responses",
+			a:    func(tc *models.TestCase) { tc.HttpResp.Binary = "AwQF" },
+			b:    func(tc *models.TestCase) { tc.HttpResp.Binary = "AwQG" },
+		},
+		{
+			name: "form fields in another order",
+			a:    form(models.FormData{Key: "a", Values: []string{"1"}}, models.FormData{Key: "b", Values: []string{"x"}}),
+			b:    form(models.FormData{Key: "b", Values: []string{"x"}}, models.FormData{Key: "a", Values: []string{"1"}}),
+			same: true,
+		},
+		{
+			name: "other form field names",
+			a:    form(models.FormData{Key: "a", Values: []string{"1"}}),
+			b:    form(models.FormData{Key: "b", Values: []string{"1"}}),
+		},
+		{
+			name: "form values of the same JSON shape",
+			a:    form(models.FormData{Key: "meta", Values: []string{`{"id":1}`}}),
+			b:    form(models.FormData{Key: "meta", Values: []string{`{"id":2}`}}),
+			same: true,
+		},
+		{
+			name: "same uploaded files at other paths",
+			a:    form(models.FormData{Key: "file", Paths: []string{"a.png"}, Contents: [][]byte{{1, 2}}}),
+			b:    form(models.FormData{Key: "file", Paths: []string{"test-3-files/0/a.png"}, Contents: [][]byte{{1, 2}}}),
+			same: true,
+		},
+		{
+			name: "other uploaded file contents",
+			a:    form(models.FormData{Key: "file", Paths: []string{"a.png"}, Contents: [][]byte{{1, 2}}}),
+			b:    form(models.FormData{Key: "file", Paths: []string{"a.png"}, Contents: [][]byte{{1, 3}}}),
+		},
+		{
+			name: "other uploaded file names",
+			a:    form(models.FormData{Key: "file", Paths: []string{"a.png"}, Contents: [][]byte{{1, 2}}}),
+			b:    form(models.FormData{Key: "file", Paths: []string{"b.png"}, Contents: [][]byte{{1, 2}}}),
+		},
+		{
+			name: "streams of other lengths",
+			a: func(tc *models.TestCase) {
+				tc.HttpResp.StreamEvents = []models.HttpStreamEvent{{Data: `{"n":1}`}, {Data: `{"n":2}`}}
+			},
+			b: func(tc *models.TestCase) {
+				tc.HttpResp.StreamEvents = []models.HttpStreamEvent{{Data: `{"n":3}`, Delay: 5}}
+			},
+			same: true,
+		},
+		{
+			name: "streams of other event shapes",
+			a:    func(tc *models.TestCase) { tc.HttpResp.StreamEvents = []models.HttpStreamEvent{{Data: `{"n":1}`}} },
+			b:    func(tc *models.TestCase) { tc.HttpResp.StreamEvents = []models.HttpStreamEvent{{Data: `{"n":"1"}`}} },
+		},
+		{
+			name: "streamed and plain responses",
+			a:    func(tc *models.TestCase) { tc.HttpResp.StreamEvents = []models.HttpStreamEvent{{Data: `{"id":42}`}} },
+		},
+		{
+			name:   "testcases of other kinds aren't deduplicated",
+			a:      func(tc *models.TestCase) { tc.Kind = models.Kind("Unknown") },
+			b:      func(tc *models.TestCase) { tc.Kind = models.Kind("Unknown") },
+			same:   true,
+			emptyA: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := base(), base()
+			if tt.a != nil {
+				tt.a(a)
+			}
+			if tt.b != nil {
+				tt.b(b)
+			}
+			sigA, sigB := Signature(a), Signature(b)
+			if (sigA == sigB) != tt.same {
+				t.Errorf("Signature() = %q and %q, want same %v", sigA, sigB, tt.same)
+			}
+			if (sigA == "") != tt.emptyA {
+				t.Errorf("Signature() = %q, want empty %v", sigA, tt.emptyA)
+			}
+		})
+	}
+}
+
+func TestOrphanMocks(t *testing.T) {
+	base := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+	httpTc := func(start, end int) *models.TestCase {
+		return &models.TestCase{
+			Kind:     models.HTTP,
+			HttpReq:  models.HttpReq{Timestamp: at(start)},
+			HttpResp: models.HttpResp{Timestamp: at(end)},
+		}
+	}
+	mock := func(sec int) *models.Mock {
+		m := &models.Mock{}
+		if sec >= 0 {
+			m.Spec.Timestamp = at(sec)
+		}
+		return m
+	}
+	configMock := func(sec int) *models.Mock {
+		m := mock(sec)
+		m.Spec.Metadata = map[string]string{"type": "config"}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		removed []*models.TestCase
+		kept    []*models.TestCase
+		mocks   []*models.Mock
+		want    []bool
+	}{
+		{
+			name:    "mocks inside the windows of the removed testcases",
+			removed: []*models.TestCase{httpTc(30, 40)},
+			kept:    []*models.TestCase{httpTc(10, 20)},
+			mocks:   []*models.Mock{mock(5), mock(15), mock(35), mock(50)},
+			want:    []bool{false, false, true, false},
+		},
+		{
+			name:    "overlapping windows give the mock to the latest started testcase",
+			removed: []*models.TestCase{httpTc(20, 30)},
+			kept:    []*models.TestCase{httpTc(10, 40)},
+			mocks:   []*models.Mock{mock(15), mock(25), mock(35)},
+			want:    []bool{false, true, false},
+		},
+		{
+			name:    "a kept testcase started during a removed one keeps its mocks",
+			removed: []*models.TestCase{httpTc(10, 40)},
+			kept:    []*models.TestCase{httpTc(20, 30)},
+			mocks:   []*models.Mock{mock(15), mock(25), mock(35)},
+			want:    []bool{true, false, true},
+		},
+		{
+			name:    "config mocks and mocks without timestamps are shared",
+			removed: []*models.TestCase{httpTc(10, 20)},
+			mocks:   []*models.Mock{configMock(15), mock(-1), mock(15)},
+			want:    []bool{false, false, true},
+		},
+		{
+			name:    "removed testcases without timestamps",
+			removed: []*models.TestCase{{Kind: models.HTTP}},
+			mocks:   []*models.Mock{mock(15)},
+			want:    []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orphanMocks(tt.mocks, tt.removed, tt.kept); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orphanMocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneMocks(t *testing.T) {
+	base := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	ys := &Yaml{MockPath: dir, Logger: zap.NewNop()}
+	for i, sec := range []int{5, 15, 25} {
+		mock := &models.Mock{
+			Version: models.V1Beta2,
+			Kind:    models.HTTP,
+			Spec: models.MockSpec{
+				HttpReq:   &models.HttpReq{Method: models.Method("GET"), URL: fmt.Sprintf("http://db.local/%d", i)},
+				HttpResp:  &models.HttpResp{StatusCode: 200},
+				Timestamp: base.Add(time.Duration(sec) * time.Second),
+			},
+		}
+		if err := ys.WriteMock(mock); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removed := []*models.TestCase{{
+		Kind:     models.HTTP,
+		HttpReq:  models.HttpReq{Timestamp: base.Add(10 * time.Second)},
+		HttpResp: models.HttpResp{Timestamp: base.Add(20 * time.Second)},
+	}}
+
+	for _, apply := range []bool{false, true} {
+		n, err := PruneMocks(dir, removed, nil, apply, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("PruneMocks(apply: %v) = %d, want 1", apply, n)
+		}
+	}
+	_, mocks, err := ys.ReadMocks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, mock := range mocks {
+		urls = append(urls, mock.Spec.HttpReq.URL)
+	}
+	if want := []string{"http://db.local/0", "http://db.local/2"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("mocks after pruning = %v, want %v", urls, want)
+	}
+}
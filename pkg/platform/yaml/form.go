@@ -0,0 +1,56 @@
+package yaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// writeFormFiles writes the files uploaded in the multipart request of the testcase into the
+// <testcase>-files directory next to the testcase, and sets their paths relative to it.
+func writeFormFiles(tcsPath, tcsName string, form []models.FormData) error {
+	for i := range form {
+		for j, path := range form[i].Paths {
+			if j >= len(form[i].Contents) {
+				continue
+			}
+			rel := filepath.Join(tcsName+"-files", strconv.Itoa(i), filepath.Base(path))
+			err := os.MkdirAll(filepath.Dir(filepath.Join(tcsPath, rel)), os.ModePerm)
+			if err != nil {
+				return fmt.Errorf(Emoji+"failed to create the directory for the uploaded files. error: %v", err)
+			}
+			err = os.WriteFile(filepath.Join(tcsPath, rel), form[i].Contents[j], os.ModePerm)
+			if err != nil {
+				return fmt.Errorf(Emoji+"failed to write the uploaded file %s. error: %v", path, err)
+			}
+			form[i].Paths[j] = filepath.ToSlash(rel)
+		}
+	}
+	return nil
+}
+
+// readFormFiles reads the contents of the files uploaded in the multipart request of the
+// testcase. The files have to be in the <testcase>-files directory next to the testcase, so
+// that an edited testcase can't read the other files of the machine.
+func readFormFiles(tcsPath, tcsName string, form []models.FormData) error {
+	dir := tcsName + "-files" + string(filepath.Separator)
+	for i := range form {
+		form[i].Contents = nil
+		for _, path := range form[i].Paths {
+			rel := filepath.Clean(filepath.FromSlash(path))
+			if filepath.IsAbs(path) || filepath.IsAbs(rel) || !strings.HasPrefix(rel, dir) {
+				return fmt.Errorf(Emoji+"the uploaded file %s of the testcase is not in the %s directory", path, dir)
+			}
+			content, err := os.ReadFile(filepath.Join(tcsPath, rel))
+			if err != nil {
+				return fmt.Errorf(Emoji+"failed to read the uploaded file of the testcase. error: %v", err)
+			}
+			form[i].Contents = append(form[i].Contents, content)
+		}
+	}
+	return nil
+}
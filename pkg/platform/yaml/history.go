@@ -0,0 +1,49 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/pkg/models"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// ReadTestHistory reads the history.yaml of the test reports directory. An empty history is
+// returned if the testcases have not been run before.
+func ReadTestHistory(path string) (*models.TestHistory, error) {
+	history := &models.TestHistory{}
+	data, err := os.ReadFile(filepath.Join(path, "history.yaml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		err = yamlLib.Unmarshal(data, history)
+		if err != nil {
+			return nil, fmt.Errorf(Emoji+"failed to decode the test history. error: %v", err.Error())
+		}
+	}
+	if history.TestSets == nil {
+		history.TestSets = map[string]map[string][]models.TestStatus{}
+	}
+	return history, nil
+}
+
+// WriteTestHistory writes the history of the test runs into the history.yaml of the test
+// reports directory.
+func WriteTestHistory(path string, history *models.TestHistory) error {
+	data, err := yamlLib.Marshal(history)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to marshal the test history. error: %v", err.Error())
+	}
+	err = os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(path, "history.yaml"), data, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to write the test history. error: %v", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package yaml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/pkg/models"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// WriteLoadReport writes the load report next to the test report of the same name, as
+// <report name>-load.yaml.
+func WriteLoadReport(path string, doc *models.LoadReport) error {
+	data, err := yamlLib.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to marshal the load report. error: %v", err.Error())
+	}
+	err = os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(path, doc.Name+"-load.yaml"), data, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf(Emoji+"failed to write the load report. error: %v", err.Error())
+	}
+	return nil
+}
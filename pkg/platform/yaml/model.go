@@ -29,36 +29,40 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*NetworkTrafficDoc,
 		Name:    tc.Name,
 	}
 	// mocks := []NetworkTrafficDoc{}
-	// find noisy fields
-	m, err := FlattenHttpResponse(pkg.ToHttpHeader(tc.HttpResp.Header), tc.HttpResp.Body)
-	if err != nil {
-		msg := "error in flattening http response"
-		logger.Error(msg, zap.Error(err))
-	}
 	// noise := httpSpec.Assertions["noise"]
 	noise := tc.Noise
 
-	noise = append(noise, FindNoisyFields(m, func(k string, vals []string) bool {
-		// check if k is date
-		for _, v := range vals {
-			if pkg.IsTime(v) {
-				return true
-			}
+	switch tc.Kind {
+	case models.HTTP:
+		// find noisy fields
+		m, err := FlattenHttpResponse(pkg.ToHttpHeader(tc.HttpResp.Header), tc.HttpResp.Body)
+		if err != nil {
+			msg := "error in flattening http response"
+			logger.Error(msg, zap.Error(err))
 		}
+		noise = append(noise, FindNoisyFields(m, func(k string, vals []string) bool {
+			// check if k is date
+			for _, v := range vals {
+				if pkg.IsTime(v) {
+					return true
+				}
+			}
 
-		// maybe we need to concatenate the values
-		return pkg.IsTime(strings.Join(vals, ", "))
-	})...)
+			// maybe we need to concatenate the values
+			return pkg.IsTime(strings.Join(vals, ", "))
+		})...)
+		// the noise of a rewritten testcase already contains its noisy fields
+		noise = uniqueNoise(noise)
 
-	switch tc.Kind {
-	case models.HTTP:
-		err := doc.Spec.Encode(spec.HttpSpec{
+		err = doc.Spec.Encode(spec.HttpSpec{
 			Request:  tc.HttpReq,
 			Response: tc.HttpResp,
 			Created:  tc.Created,
 			Assertions: map[string][]string{
 				"noise": noise,
 			},
+			Compare: tc.Compare,
+			Extract: tc.Extract,
 		})
 		if err != nil {
 			logger.Error("failed to encode testcase into a yaml doc", zap.Error(err))
@@ -68,6 +72,19 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*NetworkTrafficDoc,
 		// if err != nil {
 		// 	return nil, err
 		// }
+	case models.GRPC_EXPORT:
+		err := doc.Spec.Encode(spec.GrpcSpec{
+			GrpcReq:  tc.GrpcReq,
+			GrpcResp: tc.GrpcResp,
+			Created:  tc.Created,
+			Assertions: map[string][]string{
+				"noise": noise,
+			},
+		})
+		if err != nil {
+			logger.Error("failed to encode the gRPC testcase into a yaml doc", zap.Error(err))
+			return nil, err
+		}
 	default:
 		logger.Error("failed to marshal the testcase into yaml due to invalid kind of testcase")
 		return nil, errors.New("type of testcases is invalid")
@@ -117,6 +134,7 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*NetworkTrafficDoc, erro
 			Requests:  requests,
 			Response:  responses,
 			CreatedAt: mock.Spec.Created,
+			Timestamp: mock.Spec.Timestamp,
 			// RequestHeader:  *mock.Spec.MongoRequestHeader,
 			// ResponseHeader: *mock.Spec.MongoResponseHeader,
 		}
@@ -140,10 +158,11 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*NetworkTrafficDoc, erro
 
 	case models.HTTP:
 		httpSpec := spec.HttpSpec{
-			Metadata: mock.Spec.Metadata,
-			Request:  *mock.Spec.HttpReq,
-			Response: *mock.Spec.HttpResp,
-			Created:  mock.Spec.Created,
+			Metadata:  mock.Spec.Metadata,
+			Request:   *mock.Spec.HttpReq,
+			Response:  *mock.Spec.HttpResp,
+			Created:   mock.Spec.Created,
+			Timestamp: mock.Spec.Timestamp,
 			// Objects:  mock.Spec.OutputBinary,
 		}
 		err := yamlDoc.Spec.Encode(httpSpec)
@@ -157,6 +176,7 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*NetworkTrafficDoc, erro
 			// Objects:  mock.Spec.OutputBinary,
 			GenericRequests:  mock.Spec.GenericRequests,
 			GenericResponses: mock.Spec.GenericResponses,
+			Timestamp:        mock.Spec.Timestamp,
 		}
 		err := yamlDoc.Spec.Encode(genericSpec)
 		if err != nil {
@@ -177,6 +197,7 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*NetworkTrafficDoc, erro
 			// Objects:  mock.Spec.OutputBinary,
 			PostgresRequests:  mock.Spec.PostgresRequests,
 			PostgresResponses: mock.Spec.PostgresResponses,
+			Timestamp:         mock.Spec.Timestamp,
 		}
 
 		err := yamlDoc.Spec.Encode(postgresSpec)
@@ -186,8 +207,9 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*NetworkTrafficDoc, erro
 		}
 	case models.GRPC_EXPORT:
 		gRPCSpec := spec.GrpcSpec{
-			GrpcReq:  *mock.Spec.GRPCReq,
-			GrpcResp: *mock.Spec.GRPCResp,
+			GrpcReq:   *mock.Spec.GRPCReq,
+			GrpcResp:  *mock.Spec.GRPCResp,
+			Timestamp: mock.Spec.Timestamp,
 		}
 		err := yamlDoc.Spec.Encode(gRPCSpec)
 		if err != nil {
@@ -225,6 +247,8 @@ func Decode(yamlTestcase *NetworkTrafficDoc, logger *zap.Logger) (*models.TestCa
 		tc.HttpReq = httpSpec.Request
 		tc.HttpResp = httpSpec.Response
 		tc.Noise = httpSpec.Assertions["noise"]
+		tc.Compare = httpSpec.Compare
+		tc.Extract = httpSpec.Extract
 	// mocks, err := decodeMocks(yamlMocks, logger)
 	// tc.Mocks = mocks
 	// unmarshal its mocks from yaml docs to go struct
@@ -235,8 +259,10 @@ func Decode(yamlTestcase *NetworkTrafficDoc, logger *zap.Logger) (*models.TestCa
 			logger.Error(Emoji+"failed to unmarshal a yaml doc into the gRPC testcase", zap.Error(err))
 			return nil, err
 		}
+		tc.Created = grpcSpec.Created
 		tc.GrpcReq = grpcSpec.GrpcReq
 		tc.GrpcResp = grpcSpec.GrpcResp
+		tc.Noise = grpcSpec.Assertions["noise"]
 		//mocks, err := decodeMocks(yamlMocks, logger)
 		//tc.Mocks = mocks
 	default:
@@ -268,7 +294,8 @@ func decodeMocks(yamlMocks []*NetworkTrafficDoc, logger *zap.Logger) ([]*models.
 				HttpReq:  &httpSpec.Request,
 				HttpResp: &httpSpec.Response,
 				// OutputBinary: httpSpec.Objects,
-				Created: httpSpec.Created,
+				Created:   httpSpec.Created,
+				Timestamp: httpSpec.Timestamp,
 			}
 		case models.Mongo:
 			mongoSpec := spec.MongoSpec{}
@@ -297,7 +324,8 @@ func decodeMocks(yamlMocks []*NetworkTrafficDoc, logger *zap.Logger) ([]*models.
 				return nil, err
 			}
 			mock.Spec = models.MockSpec{GRPCResp: &grpcSpec.GrpcResp,
-				GRPCReq: &grpcSpec.GrpcReq,
+				GRPCReq:   &grpcSpec.GrpcReq,
+				Timestamp: grpcSpec.Timestamp,
 			}
 		case models.GENERIC:
 			genericSpec := spec.GenericSpec{}
@@ -311,6 +339,7 @@ func decodeMocks(yamlMocks []*NetworkTrafficDoc, logger *zap.Logger) ([]*models.
 				// OutputBinary: genericSpec.Objects,
 				GenericRequests:  genericSpec.GenericRequests,
 				GenericResponses: genericSpec.GenericResponses,
+				Timestamp:        genericSpec.Timestamp,
 			}
 
 		case models.Postgres:
@@ -335,6 +364,7 @@ func decodeMocks(yamlMocks []*NetworkTrafficDoc, logger *zap.Logger) ([]*models.
 				// OutputBinary: genericSpec.Objects,
 				GenericRequests:  genericSpec.PostgresRequests,
 				GenericResponses: genericSpec.PostgresResponses,
+				Timestamp:        genericSpec.Timestamp,
 			}
 		default:
 			logger.Error("failed to unmarshal a mock yaml doc of unknown type", zap.Any("type", m.Kind))
@@ -348,8 +378,9 @@ func decodeMocks(yamlMocks []*NetworkTrafficDoc, logger *zap.Logger) ([]*models.
 
 func decodeMongoMessage(yamlSpec *spec.MongoSpec, logger *zap.Logger) (*models.MockSpec, error) {
 	mockSpec := models.MockSpec{
-		Metadata: yamlSpec.Metadata,
-		Created:  yamlSpec.CreatedAt,
+		Metadata:  yamlSpec.Metadata,
+		Created:   yamlSpec.CreatedAt,
+		Timestamp: yamlSpec.Timestamp,
 		// MongoRequestHeader:  &yamlSpec.RequestHeader,
 		// MongoResponseHeader: &yamlSpec.ResponseHeader,
 	}
@@ -476,3 +507,17 @@ func decodeMongoMessage(yamlSpec *spec.MongoSpec, logger *zap.Logger) (*models.M
 	mockSpec.MongoResponses = responses
 	return &mockSpec, nil
 }
+
+// uniqueNoise removes the duplicate noisy fields, keeping the first occurrence of each field.
+func uniqueNoise(noise []string) []string {
+	seen := map[string]bool{}
+	res := []string{}
+	for _, n := range noise {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		res = append(res, n)
+	}
+	return res
+}
@@ -0,0 +1,567 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// detector is a built-in rule to find the secrets by their headers, fields or format.
+type detector struct {
+	headers []string
+	fields  []string
+	pattern string
+}
+
+var detectors = map[string]detector{
+	"auth":     {headers: []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Auth-Token"}},
+	"cookie":   {headers: []string{"Cookie", "Set-Cookie"}},
+	"password": {fields: []string{"password", "passwd", "pwd", "secret", "client_secret", "api_key", "apikey", "access_token", "refresh_token", "id_token"}},
+	"jwt":      {pattern: `eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`},
+	"aws":      {pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	// the private keys are redacted as a whole, along with their armour
+	"private-key": {pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	// the password of the credentials in the connection urls, e.g. postgres://user:pass@db:5432
+	"db-url": {pattern: `[a-zA-Z][a-zA-Z0-9+.\-]*://[^:/@\s]+:([^@\s/]+)@`},
+	// the password messages of the postgres mocks
+	"postgres": {},
+}
+
+// Redactor replaces the secrets in the testcases and mocks with placeholders, by the rules of
+// the redact options. A nil redactor doesn't redact anything.
+type Redactor struct {
+	headers  map[string]bool
+	fields   []string
+	patterns []*regexp.Regexp
+	postgres bool
+	salt     string
+}
+
+// NewRedactor compiles the rules of the redact options. It returns nil without any rules.
+func NewRedactor(opts models.RedactOptions) (*Redactor, error) {
+	if len(opts.Headers) == 0 && len(opts.Fields) == 0 && len(opts.Patterns) == 0 && len(opts.Detectors) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{headers: map[string]bool{}, salt: opts.Salt}
+	headers, fields, patterns := opts.Headers, opts.Fields, opts.Patterns
+	names := opts.Detectors
+	for _, name := range names {
+		if name == "all" {
+			names = make([]string, 0, len(detectors))
+			for name := range detectors {
+				names = append(names, name)
+			}
+			break
+		}
+	}
+	for _, name := range names {
+		d, ok := detectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction detector %s", name)
+		}
+		headers = append(headers, d.headers...)
+		fields = append(fields, d.fields...)
+		if d.pattern != "" {
+			patterns = append(patterns, d.pattern)
+		}
+		r.postgres = r.postgres || name == "postgres"
+	}
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range fields {
+		r.fields = append(r.fields, strings.ToLower(field))
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s. error: %v", pattern, err.Error())
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Testcase redacts the secrets of the testcase, and returns the number of redacted values. The
+// redacted fields of the http response are added to the noise of the testcase, since the
+// application responds with the actual secrets at replay.
+func (r *Redactor) Testcase(tc *models.TestCase) int {
+	if r == nil {
+		return 0
+	}
+	switch tc.Kind {
+	case models.HTTP:
+		count := r.request(&tc.HttpReq)
+		noise := r.response(&tc.HttpResp)
+		for _, field := range noise {
+			if !Contains(tc.Noise, field) {
+				tc.Noise = append(tc.Noise, field)
+			}
+		}
+		return count + len(noise)
+	case models.GRPC_EXPORT:
+		return r.headerMap(tc.GrpcReq.Headers.OrdinaryHeaders, nil)
+	}
+	return 0
+}
+
+// Mock redacts the secrets of the mock, and returns the number of redacted values. Only the
+// requests of the binary mocks are redacted, since their responses are replayed byte for byte.
+func (r *Redactor) Mock(mock *models.Mock) int {
+	if r == nil {
+		return 0
+	}
+	count := 0
+	for key, value := range mock.Spec.Metadata {
+		if redacted := r.text(value); redacted != value {
+			mock.Spec.Metadata[key] = redacted
+			count++
+		}
+	}
+	switch mock.Kind {
+	case models.HTTP:
+		if mock.Spec.HttpReq != nil {
+			count += r.request(mock.Spec.HttpReq)
+		}
+		if mock.Spec.HttpResp != nil {
+			count += len(r.response(mock.Spec.HttpResp))
+		}
+	case models.GENERIC:
+		count += r.payloads(mock.Spec.GenericRequests, false)
+	case models.Postgres:
+		// the recorded postgres mocks have their requests in PostgresRequests, and the decoded
+		// ones in GenericRequests
+		count += r.payloads(mock.Spec.PostgresRequests, true)
+		count += r.payloads(mock.Spec.GenericRequests, true)
+	case models.GRPC_EXPORT:
+		if mock.Spec.GRPCReq != nil {
+			count += r.headerMap(mock.Spec.GRPCReq.Headers.OrdinaryHeaders, nil)
+		}
+	}
+	return count
+}
+
+// RedactMocks redacts the mocks of the mocks.yaml of the test set in place, and returns the
+// number of redacted values. The mocks without any secrets are written back as they were read.
+func RedactMocks(path string, r *Redactor, logger *zap.Logger) (int, error) {
+	if _, err := os.Stat(filepath.Join(path, "mocks.yaml")); err != nil {
+		return 0, nil
+	}
+	docs, err := read(path, "mocks")
+	if err != nil {
+		logger.Error("failed to read the mocks from yaml", zap.Error(err), zap.Any("path", path))
+		return 0, err
+	}
+	count := 0
+	for i, doc := range docs {
+		mocks, err := decodeMocks([]*NetworkTrafficDoc{doc}, logger)
+		if err != nil {
+			return 0, err
+		}
+		n := r.Mock(mocks[0])
+		if n == 0 {
+			continue
+		}
+		if mocks[0].Kind == models.Postgres {
+			mocks[0].Spec.PostgresRequests = mocks[0].Spec.GenericRequests
+			mocks[0].Spec.PostgresResponses = mocks[0].Spec.GenericResponses
+		}
+		docs[i], err = EncodeMock(mocks[0], logger)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := writeMocks(path, docs, logger); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *Redactor) request(req *models.HttpReq) int {
+	count := r.headerMap(req.Header, nil)
+	if redacted, n := r.query(req.URL); n > 0 {
+		req.URL = redacted
+		count += n
+	}
+	for key, value := range req.URLParams {
+		if r.field(key) && value != "" && !isPlaceholder(value) {
+			req.URLParams[key] = r.placeholder(value)
+			count++
+		} else if redacted := r.text(value); redacted != value {
+			req.URLParams[key] = redacted
+			count++
+		}
+	}
+	body, fields := r.body(req.Body, req.Header)
+	req.Body = body
+	return count + len(fields)
+}
+
+// response redacts the http response and returns the noise of its redacted fields.
+func (r *Redactor) response(resp *models.HttpResp) []string {
+	var noise []string
+	r.headerMap(resp.Header, &noise)
+	body, fields := r.body(resp.Body, resp.Header)
+	resp.Body = body
+	for _, field := range fields {
+		if field == "" {
+			noise = append(noise, "body")
+			continue
+		}
+		noise = append(noise, "body."+field)
+	}
+	return noise
+}
+
+// headerMap redacts the values of the headers, and collects their noise.
+func (r *Redactor) headerMap(header map[string]string, noise *[]string) int {
+	count := 0
+	for key, value := range header {
+		redacted := value
+		if r.headers[http.CanonicalHeaderKey(key)] {
+			redacted = r.headerValue(value)
+		} else {
+			redacted = r.text(value)
+		}
+		if redacted == value {
+			continue
+		}
+		header[key] = redacted
+		count++
+		if noise != nil {
+			*noise = append(*noise, "header."+key)
+		}
+	}
+	return count
+}
+
+// headerValue redacts the value of the header, keeping the scheme of the credentials, e.g.
+// "Bearer {{REDACTED_1a2b3c4d5e6f}}".
+func (r *Redactor) headerValue(value string) string {
+	if value == "" || isPlaceholder(value) {
+		return value
+	}
+	scheme, credentials, ok := strings.Cut(value, " ")
+	if ok && credentials != "" && isScheme(scheme) {
+		if isPlaceholder(credentials) {
+			return value
+		}
+		return scheme + " " + r.placeholder(credentials)
+	}
+	return r.placeholder(value)
+}
+
+func isScheme(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// query redacts the values of the query parameters of the url which are redacted fields. The
+// raw query is edited in place, so that the placeholders aren't escaped.
+func (r *Redactor) query(rawURL string) (string, int) {
+	base, query, ok := strings.Cut(rawURL, "?")
+	count := 0
+	if ok {
+		params := strings.Split(query, "&")
+		for i, param := range params {
+			key, value, ok := strings.Cut(param, "=")
+			name, err := url.QueryUnescape(key)
+			if !ok || err != nil || !r.field(name) || value == "" || isPlaceholder(value) {
+				continue
+			}
+			params[i] = key + "=" + r.placeholder(value)
+			count++
+		}
+		query = strings.Join(params, "&")
+		rawURL = base + "?" + query
+	}
+	if redacted := r.text(rawURL); redacted != rawURL {
+		return redacted, count + 1
+	}
+	return rawURL, count
+}
+
+// body redacts the JSON, form or text body, and returns the flattened paths of its redacted
+// fields. The path of a redacted text body is empty.
+func (r *Redactor) body(body string, header map[string]string) (string, []string) {
+	if body == "" {
+		return body, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	var v interface{}
+	if decoder.Decode(&v) == nil && !decoder.More() {
+		// the redacted values are replaced in the body, so that the rest of the body keeps its
+		// order and formatting, and still matches the requests of the application at replay
+		var (
+			fields []string
+			spans  []jsonSpan
+		)
+		decoder = json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		if err := r.json(decoder, body, "", &spans, &fields); err != nil || len(spans) == 0 {
+			return body, nil
+		}
+		var res strings.Builder
+		last := 0
+		for _, span := range spans {
+			res.WriteString(body[last:span.start])
+			res.WriteString(span.value)
+			last = span.end
+		}
+		res.WriteString(body[last:])
+		return res.String(), fields
+	}
+
+	for key, value := range header {
+		if http.CanonicalHeaderKey(key) == "Content-Type" && strings.HasPrefix(value, "application/x-www-form-urlencoded") {
+			if redacted, fields := r.form(body); len(fields) > 0 {
+				return redacted, fields
+			}
+		}
+	}
+	if redacted := r.text(body); redacted != body {
+		return redacted, []string{""}
+	}
+	return body, nil
+}
+
+// jsonSpan is a redacted value of a JSON body, at the byte offsets [start, end) of the body.
+type jsonSpan struct {
+	start, end int
+	value      string
+}
+
+// json reads the next JSON value of the decoder, and collects the spans of the values of the
+// redacted fields and of the strings with secrets. The elements of the arrays share the path of
+// the array, as in Flatten.
+func (r *Redactor) json(decoder *json.Decoder, body, path string, spans *[]jsonSpan, fields *[]string) error {
+	start := valueStart(body, int(decoder.InputOffset()))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		for decoder.More() {
+			if token == '[' {
+				if err := r.json(decoder, body, path, spans, fields); err != nil {
+					return err
+				}
+				continue
+			}
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if !r.fieldPath(fieldPath, key) {
+				if err := r.json(decoder, body, fieldPath, spans, fields); err != nil {
+					return err
+				}
+				continue
+			}
+			fieldStart := valueStart(body, int(decoder.InputOffset()))
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			if s, ok := value.(string); value == nil || ok && isPlaceholder(s) {
+				continue
+			}
+			*spans = append(*spans, jsonSpan{start: fieldStart, end: int(decoder.InputOffset()), value: jsonString(r.placeholder(jsonText(value)))})
+			*fields = append(*fields, fieldPath)
+		}
+		// the closing delimiter of the object or the array
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	case string:
+		if redacted := r.text(token); redacted != token {
+			*spans = append(*spans, jsonSpan{start: start, end: int(decoder.InputOffset()), value: jsonString(redacted)})
+			*fields = append(*fields, path)
+		}
+	}
+	return nil
+}
+
+// valueStart returns the offset of the JSON value following the offset, by skipping the
+// whitespace and the separators in between.
+func valueStart(body string, offset int) int {
+	for offset < len(body) && strings.IndexByte(" \t\r\n:,", body[offset]) != -1 {
+		offset++
+	}
+	return offset
+}
+
+// jsonString encodes the string as a JSON string, without escaping the HTML characters.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func jsonText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// form redacts the values of the redacted fields of the url encoded form body.
+func (r *Redactor) form(body string) (string, []string) {
+	var fields []string
+	params := strings.Split(body, "&")
+	for i, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		name, err := url.QueryUnescape(key)
+		if !ok || err != nil || !r.field(name) || value == "" || isPlaceholder(value) {
+			continue
+		}
+		params[i] = key + "=" + r.placeholder(value)
+		fields = append(fields, name)
+	}
+	return strings.Join(params, "&"), fields
+}
+
+// field checks whether the key is a redacted field at any depth.
+func (r *Redactor) field(key string) bool {
+	return r.fieldPath(key, key)
+}
+
+// fieldPath checks whether the field at the flattened path is redacted, either by its path or
+// by its key.
+func (r *Redactor) fieldPath(path, key string) bool {
+	path, key = strings.ToLower(path), strings.ToLower(key)
+	for _, field := range r.fields {
+		if field == path || !strings.Contains(field, ".") && field == key {
+			return true
+		}
+	}
+	return false
+}
+
+// text redacts the secrets matching the patterns in the text. The placeholders in the text are
+// left as they are, so that a redacted text is redacted again without any change.
+func (r *Redactor) text(s string) string {
+	if len(r.patterns) == 0 || s == "" {
+		return s
+	}
+	var res strings.Builder
+	last := 0
+	for _, loc := range pkg.RedactedPattern.FindAllStringIndex(s, -1) {
+		res.WriteString(r.redactPatterns(s[last:loc[0]]))
+		res.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	res.WriteString(r.redactPatterns(s[last:]))
+	return res.String()
+}
+
+func (r *Redactor) redactPatterns(s string) string {
+	for _, re := range r.patterns {
+		var res strings.Builder
+		last := 0
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			// the first group is redacted when the regex has one, or else the whole match
+			start, end := loc[0], loc[1]
+			if len(loc) > 2 {
+				start, end = loc[2], loc[3]
+			}
+			if start < 0 || start == end {
+				continue
+			}
+			res.WriteString(s[last:start])
+			res.WriteString(r.placeholder(s[start:end]))
+			last = end
+		}
+		res.WriteString(s[last:])
+		s = res.String()
+	}
+	return s
+}
+
+func (r *Redactor) payloads(payloads []models.GenericPayload, postgres bool) int {
+	count := 0
+	for i := range payloads {
+		for j, msg := range payloads[i].Message {
+			if redacted := r.payload(msg, postgres); redacted != msg.Data {
+				payloads[i].Message[j].Data = redacted
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// payload redacts the request payload of a binary mock. The password messages of the postgres
+// mocks are redacted with the postgres detector, and the secrets in the text payloads with the
+// patterns.
+func (r *Redactor) payload(msg models.OutputBinary, postgres bool) string {
+	if msg.Type == "utf-8" {
+		return r.text(msg.Data)
+	}
+	if !postgres || !r.postgres {
+		return msg.Data
+	}
+	data, err := base64.StdEncoding.DecodeString(msg.Data)
+	if err != nil {
+		return msg.Data
+	}
+	// a password message is 'p', its length and the password terminated by a null byte. The
+	// SASL messages share its identifier, but don't end with their only null byte.
+	if len(data) < 6 || data[0] != 'p' || int(binary.BigEndian.Uint32(data[1:5])) != len(data)-1 ||
+		data[len(data)-1] != 0 || bytes.IndexByte(data[5:], 0) != len(data)-6 {
+		return msg.Data
+	}
+	password := string(data[5 : len(data)-1])
+	if password == "" || isPlaceholder(password) {
+		return msg.Data
+	}
+	redacted := []byte(r.placeholder(password))
+	res := make([]byte, 5, 5+len(redacted)+1)
+	res[0] = 'p'
+	binary.BigEndian.PutUint32(res[1:5], uint32(4+len(redacted)+1))
+	res = append(append(res, redacted...), 0)
+	return base64.StdEncoding.EncodeToString(res)
+}
+
+func (r *Redactor) placeholder(secret string) string {
+	return pkg.RedactedPlaceholder(secret, r.salt)
+}
+
+func isPlaceholder(s string) bool {
+	return pkg.RedactedPattern.FindString(s) == s
+}
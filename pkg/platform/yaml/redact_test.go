@@ -0,0 +1,134 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+)
+
+// passwordMessage frames the password into a postgres password message.
+func passwordMessage(password string) []byte {
+	msg := []byte{'p', 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:5], uint32(4+len(password)+1))
+	return append(append(msg, password...), 0)
+}
+
+func TestRedactorPayload(t *testing.T) {
+	const salt = "salt"
+	placeholder := pkg.RedactedPlaceholder("s3cret", salt)
+	binaryMsg := func(data []byte) models.OutputBinary {
+		return models.OutputBinary{Type: "binary", Data: base64.StdEncoding.EncodeToString(data)}
+	}
+	sasl := append([]byte{'p', 0, 0, 0, 0}, "SCRAM-SHA-256\x00\x00\x00\x00\x20n,,n=,r=abc"...)
+	binary.BigEndian.PutUint32(sasl[1:5], uint32(len(sasl)-1))
+
+	tests := []struct {
+		name      string
+		detectors []string
+		msg       models.OutputBinary
+		postgres  bool
+		want      []byte
+	}{
+		{name: "password message", msg: binaryMsg(passwordMessage("s3cret")), postgres: true, want: passwordMessage(placeholder)},
+		{name: "already redacted password", msg: binaryMsg(passwordMessage(placeholder)), postgres: true, want: passwordMessage(placeholder)},
+		{name: "empty password", msg: binaryMsg(passwordMessage("")), postgres: true, want: passwordMessage("")},
+		{name: "generic mock", msg: binaryMsg(passwordMessage("s3cret")), postgres: false, want: passwordMessage("s3cret")},
+		{name: "postgres detector disabled", detectors: []string{"jwt"}, msg: binaryMsg(passwordMessage("s3cret")), postgres: true, want: passwordMessage("s3cret")},
+		{name: "SASL message", msg: binaryMsg(sasl), postgres: true, want: sasl},
+		{name: "query message", msg: binaryMsg(append([]byte{'Q', 0, 0, 0, 13}, "select 1\x00"...)), postgres: true, want: append([]byte{'Q', 0, 0, 0, 13}, "select 1\x00"...)},
+		{
+			name:     "wrong length",
+			msg:      binaryMsg(append([]byte{'p', 0, 0, 0, 99}, "s3cret\x00"...)),
+			postgres: true,
+			want:     append([]byte{'p', 0, 0, 0, 99}, "s3cret\x00"...),
+		},
+		{name: "truncated message", msg: binaryMsg([]byte{'p', 0, 0}), postgres: true, want: []byte{'p', 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detectors := tt.detectors
+			if detectors == nil {
+				detectors = []string{"postgres"}
+			}
+			r, err := NewRedactor(models.RedactOptions{Detectors: detectors, Salt: salt})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := base64.StdEncoding.DecodeString(r.payload(tt.msg, tt.postgres))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("payload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorBody(t *testing.T) {
+	const salt = "salt"
+	ph := func(secret string) string { return pkg.RedactedPlaceholder(secret, salt) }
+	r, err := NewRedactor(models.RedactOptions{Fields: []string{"password", "user.token"}, Patterns: []string{`sk_live_\w+`}, Salt: salt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		want       string
+		wantFields []string
+		// quoted is set when a value which isn't a string is redacted, since its placeholder is
+		// written as a string and doesn't match the original body anymore
+		quoted bool
+	}{
+		{
+			name:       "unsorted keys and formatting are kept",
+			body:       "{\n  \"username\": \"a\",\n  \"password\" : \"s3cret\",\n  \"age\": 30\n}",
+			want:       "{\n  \"username\": \"a\",\n  \"password\" : \"" + ph("s3cret") + "\",\n  \"age\": 30\n}",
+			wantFields: []string{"password"},
+		},
+		{
+			name:       "nested field and non-string values",
+			body:       `{"z":1,"user":{"token":{"b":2,"a":1},"name":"x"},"password":12345}`,
+			want:       `{"z":1,"user":{"token":"` + ph(`{"a":1,"b":2}`) + `","name":"x"},"password":"` + ph("12345") + `"}`,
+			wantFields: []string{"user.token", "password"},
+			quoted:     true,
+		},
+		{
+			name:       "secrets in the strings of arrays",
+			body:       `{"keys": ["sk_live_abc", "public"], "n": 1.50}`,
+			want:       `{"keys": ["` + ph("sk_live_abc") + `", "public"], "n": 1.50}`,
+			wantFields: []string{"keys"},
+		},
+		{
+			name: "null and already redacted values",
+			body: `{"password": null, "user": {"token": "` + ph("t") + `"}}`,
+			want: `{"password": null, "user": {"token": "` + ph("t") + `"}}`,
+		},
+		{
+			name: "body without secrets",
+			body: `{"b": "<a&b>", "a": [1, 2]}`,
+			want: `{"b": "<a&b>", "a": [1, 2]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fields := r.body(tt.body, nil)
+			if got != tt.want {
+				t.Errorf("body() = %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("body() fields = %v, want %v", fields, tt.wantFields)
+			}
+			// the redacted body still matches the original one at replay
+			if got != tt.body && !tt.quoted && !pkg.MatchRedacted([]byte(got), []byte(tt.body)) {
+				t.Errorf("MatchRedacted(%s, %s) = false", got, tt.body)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package spec
 
-import "go.keploy.io/server/pkg/models"
+import (
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
 
 type GrpcSpec struct {
-	GrpcReq  models.GrpcReq  `json:"grpcReq" yaml:"grpcReq"`
-	GrpcResp models.GrpcResp `json:"grpcResp" yaml:"grpcResp"`
+	GrpcReq    models.GrpcReq      `json:"grpcReq" yaml:"grpcReq"`
+	GrpcResp   models.GrpcResp     `json:"grpcResp" yaml:"grpcResp"`
+	Assertions map[string][]string `json:"assertions" yaml:"assertions,omitempty"`
+	Created    int64               `json:"created" yaml:"created,omitempty"`
+	Timestamp  time.Time           `json:"timestamp" yaml:"timestamp,omitempty"`
 }
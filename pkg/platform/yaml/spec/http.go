@@ -1,6 +1,10 @@
 package spec
 
-import "go.keploy.io/server/pkg/models"
+import (
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
 
 type HttpSpec struct {
 	Metadata   map[string]string   `json:"metadata" yaml:"metadata"`
@@ -8,5 +12,8 @@ type HttpSpec struct {
 	Response   models.HttpResp        `json:"resp" yaml:"resp"`
 	Objects    []*models.OutputBinary            `json:"objects" yaml:"objects"`
 	Assertions map[string][]string `json:"assertions" yaml:"assertions,omitempty"`
+	Compare    *models.MatchOptions `json:"compare" yaml:"compare,omitempty"`
+	Extract    map[string]string   `json:"extract" yaml:"extract,omitempty"`
 	Created    int64               `json:"created" yaml:"created,omitempty"`
+	Timestamp  time.Time           `json:"timestamp" yaml:"timestamp,omitempty"`
 }
\ No newline at end of file
@@ -1,6 +1,8 @@
 package spec
 
 import (
+	"time"
+
 	"go.keploy.io/server/pkg/models"
 	"gopkg.in/yaml.v3"
 )
@@ -10,6 +12,7 @@ type MongoSpec struct {
 	Requests  []RequestYaml     `json:"requests" yaml:"requests"`
 	Response  []ResponseYaml    `json:"responses" yaml:"responses"`
 	CreatedAt int64             `json:"created" yaml:"created,omitempty"`
+	Timestamp time.Time         `json:"timestamp" yaml:"timestamp,omitempty"`
 	// RequestHeader  models.MongoHeader `json:"request_mongo_header" yaml:"request_mongo_header"`
 	// ResponseHeader models.MongoHeader `json:"response_mongo_header" yaml:"response_mongo_header"`
 	// Request        yaml.Node          `json:"mongo_request" yaml:"mongo_request"`
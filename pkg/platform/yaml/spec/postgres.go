@@ -1,6 +1,10 @@
 package spec
 
-import "go.keploy.io/server/pkg/models"
+import (
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
 
 type PostgresSpec struct {
 
@@ -10,5 +14,6 @@ type PostgresSpec struct {
 	// Objects  []*models.OutputBinary          `json:"objects" yaml:"objects"`
 	PostgresRequests  []models.GenericPayload `json:"RequestBin,omitempty"`
 	PostgresResponses []models.GenericPayload `json:"ResponseBin,omitempty"`
+	Timestamp         time.Time               `json:"timestamp" yaml:"timestamp,omitempty"`
 
 }
@@ -68,7 +68,7 @@ func (fe *TestReport) Read(ctx context.Context, path, name string) (models.TestR
 func (fe *TestReport) Write(ctx context.Context, path string, doc *models.TestReport) error {
 
 	if doc.Name == "" {
-		lastIndex, err := findLastIndex(path, fe.Logger)
+		lastIndex, err := findLastIndex(path, "report", fe.Logger)
 		if err != nil {
 			return err
 		}
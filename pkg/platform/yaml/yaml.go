@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"go.keploy.io/server/pkg/models"
 	"go.keploy.io/server/pkg/platform"
@@ -25,6 +26,17 @@ type Yaml struct {
 	MockName string
 	TcsName  string
 	Logger   *zap.Logger
+	// Dedupe skips writing the recorded testcases with the same signature as a testcase of the
+	// test set.
+	Dedupe bool
+	// Redactor replaces the secrets in the testcases and mocks before they are written.
+	Redactor *Redactor
+
+	mu sync.Mutex
+	// signatures maps the signatures of the testcases of the test set to their names
+	signatures map[string]string
+	// skipped are the duplicate testcases which weren't written
+	skipped []*models.TestCase
 }
 
 // func NewYamlStore(tcsPath, mockPath string, Logger *zap.Logger) platform.TestCaseDB {
@@ -79,8 +91,9 @@ func createYamlFile(path string, fileName string, Logger *zap.Logger) (bool, err
 	return false, nil
 }
 
-// findLastIndex returns the index for the new yaml file by reading the yaml file names in the given path directory
-func findLastIndex(path string, Logger *zap.Logger) (int, error) {
+// findLastIndex returns the index for the new yaml file by reading the yaml file names in the given path directory.
+// Only the files named by the prefix and their index are counted, e.g. test-1.yaml for the prefix test.
+func findLastIndex(path, prefix string, Logger *zap.Logger) (int, error) {
 
 	dir, err := os.OpenFile(path, os.O_RDONLY, fs.FileMode(os.O_RDONLY))
 	if err != nil {
@@ -94,20 +107,17 @@ func findLastIndex(path string, Logger *zap.Logger) (int, error) {
 
 	lastIndex := 0
 	for _, v := range files {
-		if v.Name() == "mocks.yaml" || v.Name() == "config.yaml" {
+		// e.g. the mocks, the configs, the test history and the temporary files of the rewrites
+		// which failed are left out
+		fileName := v.Name()
+		if !strings.HasPrefix(fileName, prefix+"-") || filepath.Ext(fileName) != ".yaml" {
 			continue
 		}
-		fileName := filepath.Base(v.Name())
-		fileNameWithoutExt := fileName[:len(fileName)-len(filepath.Ext(fileName))]
-		if len(strings.Split(fileNameWithoutExt, "-")) < 2 {
-			Logger.Error("failed to decode the last sequence number from yaml test", zap.Any("for the file", fileName), zap.Any("at path", path))
-			return 0, errors.New("failed to decode the last sequence number from yaml test")
-		}
-		indxStr := strings.Split(fileNameWithoutExt, "-")[1]
+		indxStr := strings.TrimSuffix(strings.TrimPrefix(fileName, prefix+"-"), ".yaml")
 		indx, err := strconv.Atoi(indxStr)
 		if err != nil {
-			Logger.Error("failed to read the sequence number from the yaml file name", zap.Error(err), zap.Any("for the file", fileName))
-			return 0, err
+			Logger.Debug("skipping the yaml file without a sequence number", zap.Any("for the file", fileName), zap.Any("at path", path))
+			continue
 		}
 		if indx > lastIndex {
 			lastIndex = indx
@@ -152,19 +162,102 @@ func (ys *Yaml) Write(path, fileName string, doc NetworkTrafficDoc) error {
 	return nil
 }
 
+// rewrite replaces the yaml file with the doc. The doc is written to a temporary file first, so
+// that the file isn't lost on a failure.
+func (ys *Yaml) rewrite(path, fileName string, doc NetworkTrafficDoc) error {
+	data, err := yamlLib.Marshal(&doc)
+	if err != nil {
+		ys.Logger.Error("failed to marshal the recorded calls into yaml", zap.Error(err), zap.Any("yaml file name", fileName))
+		return err
+	}
+	tmp := filepath.Join(path, fileName+".yaml.tmp")
+	if err := os.WriteFile(tmp, data, os.ModePerm); err != nil {
+		ys.Logger.Error("failed to write the yaml document", zap.Error(err), zap.Any("yaml file name", fileName))
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(path, fileName+".yaml")); err != nil {
+		ys.Logger.Error("failed to replace the yaml file", zap.Error(err), zap.Any("yaml file name", fileName))
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeMocks replaces the mocks.yaml of the test set with the docs. Like rewrite, the docs are
+// written to a temporary file first.
+func writeMocks(path string, docs []*NetworkTrafficDoc, logger *zap.Logger) error {
+	var data []byte
+	for i, doc := range docs {
+		if i > 0 {
+			data = append(data, []byte("---\n")...)
+		}
+		d, err := yamlLib.Marshal(doc)
+		if err != nil {
+			logger.Error("failed to marshal the mocks into yaml", zap.Error(err), zap.Any("path", path))
+			return err
+		}
+		data = append(data, d...)
+	}
+	tmp := filepath.Join(path, "mocks.yaml.tmp")
+	if err := os.WriteFile(tmp, data, os.ModePerm); err != nil {
+		logger.Error("failed to write the mocks", zap.Error(err), zap.Any("path", path))
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(path, "mocks.yaml")); err != nil {
+		logger.Error("failed to replace the mocks yaml file", zap.Error(err), zap.Any("path", path))
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
 // func (ys *yaml) Insert(tc *models.Mock, mocks []*models.Mock) error {
 func (ys *Yaml) WriteTestcase(tc *models.TestCase) error {
+	ys.Redactor.Testcase(tc)
+
+	// only the newly recorded testcases are deduplicated, not the rewritten ones
+	signature := ""
+	if ys.Dedupe && tc.Name == "" {
+		ys.mu.Lock()
+		defer ys.mu.Unlock()
+		signature = Signature(tc)
+		name, err := ys.duplicate(tc)
+		if err != nil {
+			return err
+		}
+		if name != "" && signature != "" {
+			ys.Logger.Debug("skipping the duplicate testcase", zap.Any("duplicate of", name))
+			ys.skipped = append(ys.skipped, tc)
+			return nil
+		}
+	}
 
-	var tcsName string
-	if ys.TcsName == "" {
+	var (
+		tcsName string
+		rewrite bool
+	)
+	switch {
+	case ys.TcsName != "":
+		tcsName = ys.TcsName
+	case tc.Name != "":
+		// the recorded testcase is rewritten, e.g. to update its response
+		tcsName = tc.Name
+		rewrite = true
+	default:
 		// finds the recently generated testcase to derive the sequence number for the current testcase
-		lastIndx, err := findLastIndex(ys.TcsPath, ys.Logger)
+		lastIndx, err := findLastIndex(ys.TcsPath, "test", ys.Logger)
 		if err != nil {
 			return err
 		}
 		tcsName = fmt.Sprintf("test-%v", lastIndx)
-	} else {
-		tcsName = ys.TcsName
+	}
+
+	err := writeFormFiles(ys.TcsPath, tcsName, tc.HttpReq.Form)
+	if err != nil {
+		ys.Logger.Error("failed to write the uploaded files of the testcase", zap.Error(err), zap.Any("testcase name", tcsName))
+		return err
 	}
 
 	// encode the testcase and its mocks into yaml docs
@@ -176,12 +269,19 @@ func (ys *Yaml) WriteTestcase(tc *models.TestCase) error {
 
 	// write testcase yaml
 	yamlTc.Name = tcsName
-	err = ys.Write(ys.TcsPath, tcsName, *yamlTc)
+	if rewrite {
+		err = ys.rewrite(ys.TcsPath, tcsName, *yamlTc)
+	} else {
+		err = ys.Write(ys.TcsPath, tcsName, *yamlTc)
+	}
 	if err != nil {
 		ys.Logger.Error("failed to write testcase yaml file", zap.Error(err))
 		return err
 	}
 	ys.Logger.Info("🟠 Keploy has captured test cases for the user's application.", zap.String("path", ys.TcsPath), zap.String("testcase name", ys.TcsName))
+	if signature != "" {
+		ys.signatures[signature] = tcsName
+	}
 
 	// write the mock yamls
 	// mockName := fmt.Sprintf("mock-%v", lastIndx)
@@ -260,6 +360,11 @@ func (ys *Yaml) ReadTestcase(path string, options interface{}) ([]*models.TestCa
 		if err != nil {
 			return nil, err
 		}
+		err = readFormFiles(path, tc.Name, tc.HttpReq.Form)
+		if err != nil {
+			ys.Logger.Error("failed to read the uploaded files of the testcase", zap.Error(err), zap.Any("testcase name", tc.Name))
+			return nil, err
+		}
 		// Append the encoded testcase
 		tcs = append(tcs, tc)
 	}
@@ -310,6 +415,7 @@ func (ys *Yaml) WriteMock(mock *models.Mock) error {
 		mock.Name = ys.MockName
 	}
 
+	ys.Redactor.Mock(mock)
 	mockYaml, err := EncodeMock(mock, ys.Logger)
 	if err != nil {
 		return err
@@ -0,0 +1,62 @@
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFindLastIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		files  []string
+		want   int
+	}{
+		{name: "empty directory", prefix: "test", want: 1},
+		{name: "testcases", prefix: "test", files: []string{"test-1.yaml", "test-3.yaml", "test-2.yaml"}, want: 4},
+		{name: "mocks and config of the test set", prefix: "test", files: []string{"test-1.yaml", "mocks.yaml", "config.yaml"}, want: 2},
+		{name: "leftover temporary file", prefix: "test", files: []string{"test-1.yaml", "test-2.yaml.tmp"}, want: 2},
+		{name: "testcases without a sequence number", prefix: "test", files: []string{"test-1.yaml", "test-login.yaml"}, want: 2},
+		{
+			name:   "test reports along with the history and the other reports",
+			prefix: "report",
+			files:  []string{"report-1.yaml", "report-1.xml", "report-2-load.yaml", "history.yaml"},
+			want:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := findLastIndex(dir, tt.prefix, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("findLastIndex() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteRemovesTmpFile(t *testing.T) {
+	dir := t.TempDir()
+	ys := &Yaml{Logger: zap.NewNop()}
+	// the yaml file can't be replaced by the temporary file when it is a directory
+	if err := os.MkdirAll(filepath.Join(dir, "test-1.yaml", "x"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ys.rewrite(dir, "test-1", NetworkTrafficDoc{}); err == nil {
+		t.Fatal("rewrite() returned no error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test-1.yaml.tmp")); !os.IsNotExist(err) {
+		t.Errorf("the temporary file is left after the failed rewrite. error: %v", err)
+	}
+}
@@ -63,6 +63,7 @@ func decodeGenericOutgoing(requestBuffer []byte, clientConn, destConn net.Conn,
 
 		if !matched {
 			// logger.Error("failed to match the dependency call from user application", zap.Any("request packets", len(genericRequests)))
+			h.AddDepCall(models.GENERIC, nil, EncodeRequests(genericRequests))
 			clientConn.SetReadDeadline(time.Time{})
 			logger.Debug("the genericRequests are before pass through", zap.Any("length", len(genericRequests)))
 			for _, vgen := range genericRequests {
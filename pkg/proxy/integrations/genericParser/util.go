@@ -3,10 +3,12 @@ package genericparser
 import (
 	"encoding/base64"
 	// "fmt"
+	"strings"
 	"unicode"
 
 	"github.com/agnivade/levenshtein"
 	"github.com/cloudflare/cfssl/log"
+	"go.keploy.io/server/pkg"
 	"go.keploy.io/server/pkg/hooks"
 	"go.keploy.io/server/pkg/models"
 )
@@ -24,8 +26,23 @@ func PostgresDecoder(encoded string) ([]byte, error) {
 }
 
 func fuzzymatch(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook) (bool, []models.GenericPayload) {
+	for {
+		mock := matchGeneric(tcsMocks, requestBuffers, h)
+		if mock == nil {
+			return false, nil
+		}
+		// the mock has already been consumed by a concurrent call when it isn't found, so match again
+		if h.DeleteTcsMock(mock) {
+			h.AddDepCall(models.GENERIC, mock, EncodeRequests(requestBuffers))
+			return true, mock.Spec.GenericResponses
+		}
+		tcsMocks = h.GetTcsMocks()
+	}
+}
 
-	for idx, mock := range tcsMocks {
+func matchGeneric(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook) *models.Mock {
+
+	for _, mock := range tcsMocks {
 		if len(mock.Spec.GenericRequests) == len(requestBuffers) {
 			for requestIndex, reqBuff := range requestBuffers {
 
@@ -35,11 +52,9 @@ func fuzzymatch(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook)
 				// }
 				encoded, _ := PostgresDecoder(mock.Spec.GenericRequests[requestIndex].Message[0].Data)
 
-				if string(encoded) == string(reqBuff) || mock.Spec.GenericRequests[requestIndex].Message[0].Data == bufStr {
+				if string(encoded) == string(reqBuff) || mock.Spec.GenericRequests[requestIndex].Message[0].Data == bufStr || pkg.MatchRedacted(encoded, reqBuff) {
 					log.Debug("matched in first loop")
-					tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-					h.SetTcsMocks(tcsMocks)
-					return true, mock.Spec.GenericResponses
+					return mock
 				}
 			}
 		}
@@ -65,12 +80,9 @@ func fuzzymatch(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook)
 	idx := findBinaryMatch(tcsMocks, requestBuffers, h)
 	if idx != -1 {
 		log.Debug("matched in first loop")
-		bestMatch := tcsMocks[idx].Spec.GenericResponses
-		tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-		h.SetTcsMocks(tcsMocks)
-		return true, bestMatch
+		return tcsMocks[idx]
 	}
-	return false, nil
+	return nil
 }
 
 func findBinaryMatch(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook) int {
@@ -171,3 +183,13 @@ func findStringMatch(req []string, mockString []string) int {
 	}
 	return bestMatch
 }
+
+// EncodeRequests encodes the request packets of an outgoing call in base64, one packet per
+// line, in the same way as the packets of the recorded mocks.
+func EncodeRequests(requestBuffers [][]byte) string {
+	encoded := make([]string, len(requestBuffers))
+	for i, reqBuff := range requestBuffers {
+		encoded[i] = base64.StdEncoding.EncodeToString(reqBuff)
+	}
+	return strings.Join(encoded, "\n")
+}
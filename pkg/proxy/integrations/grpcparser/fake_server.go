@@ -4,22 +4,21 @@ import (
 	"bytes"
 	"fmt"
 
+	"go.keploy.io/server/pkg/models"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/hpack"
-
-	"go.keploy.io/server/pkg/hooks"
 )
 
 type transcoder struct {
 	sic     *StreamInfoCollection
-	hook    *hooks.Hook
+	hook    Hook
 	logger  *zap.Logger
 	framer  *http2.Framer
 	decoder *hpack.Decoder
 }
 
-func NewTranscoder(framer *http2.Framer, logger *zap.Logger, h *hooks.Hook) *transcoder {
+func NewTranscoder(framer *http2.Framer, logger *zap.Logger, h Hook) *transcoder {
 	return &transcoder{
 		logger:  logger,
 		framer:  framer,
@@ -83,12 +82,22 @@ func (srv *transcoder) ProcessDataFrame(dataFrame *http2.DataFrame) error {
 
 	grpcReq := srv.sic.FetchRequestForStream(id)
 
-	// Fetch all the mocks. We can't assume that the grpc calls are made in a certain order.
-	mocks := srv.hook.GetTcsMocks()
-	mock := FilterMocksBasedOnGrpcRequest(grpcReq, mocks)
-	if mock == nil {
-		return fmt.Errorf("failed to mock the output for unrecorded outgoing grpc call")
+	actualReq := grpcReq.Headers.PseudoHeaders[":path"] + "\n" + grpcReq.Body.DecodedData
+	var mock *models.Mock
+	for {
+		// Fetch all the mocks. We can't assume that the grpc calls are made in a certain order.
+		mocks := srv.hook.GetTcsMocks()
+		mock = FilterMocksBasedOnGrpcRequest(grpcReq, mocks)
+		if mock == nil {
+			srv.hook.AddDepCall(models.GRPC_EXPORT, nil, actualReq)
+			return fmt.Errorf("failed to mock the output for unrecorded outgoing grpc call")
+		}
+		// the mock has already been consumed by a concurrent call when it isn't found, so match again
+		if srv.hook.DeleteTcsMock(mock) {
+			break
+		}
 	}
+	srv.hook.AddDepCall(models.GRPC_EXPORT, mock, actualReq)
 
 	grpcMockResp := mock.Spec.GRPCResp
 
@@ -71,7 +71,13 @@ func PrintFrame(frame http2.Frame) {
 }
 
 func ExtractHeaders(frame *http2.HeadersFrame, decoder *hpack.Decoder) (pseudoHeaders, ordinaryHeaders map[string]string, err error) {
-	hf, err := decoder.DecodeFull(frame.HeaderBlockFragment())
+	return ExtractHeaderBlock(frame.HeaderBlockFragment(), decoder)
+}
+
+// ExtractHeaderBlock decodes a complete header block, which is the fragment of a HEADERS frame
+// along with the fragments of the CONTINUATION frames following it.
+func ExtractHeaderBlock(block []byte, decoder *hpack.Decoder) (pseudoHeaders, ordinaryHeaders map[string]string, err error) {
+	hf, err := decoder.DecodeFull(block)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not decode headers: %v", err)
 	}
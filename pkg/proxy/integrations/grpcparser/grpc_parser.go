@@ -12,7 +12,6 @@ import (
 	"golang.org/x/net/http2/hpack"
 
 	"go.keploy.io/server/pkg"
-	"go.keploy.io/server/pkg/hooks"
 	"go.keploy.io/server/pkg/models"
 )
 
@@ -20,7 +19,7 @@ func IsOutgoingGRPC(buffer []byte) bool {
 	return bytes.HasPrefix(buffer[:], []byte("PRI * HTTP/2"))
 }
 
-func ProcessOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h *hooks.Hook, logger *zap.Logger) {
+func ProcessOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h Hook, logger *zap.Logger) {
 	switch models.GetMode() {
 	case models.MODE_RECORD:
 		encodeOutgoingGRPC(requestBuffer, clientConn, destConn, h, logger)
@@ -32,7 +31,7 @@ func ProcessOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h
 
 }
 
-func decodeOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h *hooks.Hook, logger *zap.Logger) {
+func decodeOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h Hook, logger *zap.Logger) {
 	framer := http2.NewFramer(clientConn, clientConn)
 	srv := NewTranscoder(framer, logger, h)
 	err := srv.ListenAndServe()
@@ -41,7 +40,7 @@ func decodeOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h *
 	}
 }
 
-func encodeOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h *hooks.Hook, logger *zap.Logger) {
+func encodeOutgoingGRPC(requestBuffer []byte, clientConn, destConn net.Conn, h Hook, logger *zap.Logger) {
 	// Send the client preface to the server. This should be the first thing sent from the client.
 	_, err := destConn.Write(requestBuffer)
 	if err != nil {
@@ -3,20 +3,31 @@ package grpcparser
 import (
 	"sync"
 
-	"go.keploy.io/server/pkg/hooks"
 	"go.keploy.io/server/pkg/models"
 )
 
+// Hook is the part of hooks.Hook that the gRPC parser depends on. Depending on an
+// interface, rather than on the hooks package, lets the ingress connection tracker of
+// the hooks package reuse the stream reassembly of this package.
+type Hook interface {
+	AppendMocks(m *models.Mock) error
+	GetTcsMocks() []*models.Mock
+	GetDepsSize() int
+	DeleteTcsMock(m *models.Mock) bool
+	AddDepCall(kind models.Kind, mock *models.Mock, actual string)
+	Recover(id int)
+}
+
 // StreamInfoCollection is a thread-safe data structure to store all communications
 // that happen in a stream for grpc. This includes the headers and data frame for the
 // request and response.
 type StreamInfoCollection struct {
-	hook       *hooks.Hook
+	hook       Hook
 	mutex      sync.Mutex
 	StreamInfo map[uint32]models.GrpcStream
 }
 
-func NewStreamInfoCollection(h *hooks.Hook) *StreamInfoCollection {
+func NewStreamInfoCollection(h Hook) *StreamInfoCollection {
 	return &StreamInfoCollection{
 		hook:       h,
 		StreamInfo: make(map[uint32]models.GrpcStream),
@@ -124,6 +135,14 @@ func (sic *StreamInfoCollection) FetchRequestForStream(streamID uint32) models.G
 	return sic.StreamInfo[streamID].GrpcReq
 }
 
+// FetchStream returns the request and response collected so far for the stream.
+func (sic *StreamInfoCollection) FetchStream(streamID uint32) models.GrpcStream {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	return sic.StreamInfo[streamID]
+}
+
 func (sic *StreamInfoCollection) ResetStream(streamID uint32) {
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
@@ -11,7 +11,6 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -337,10 +336,6 @@ func checkIfGzipped(check io.ReadCloser) (bool, *bufio.Reader) {
 
 // Decodes the mocks in test mode so that they can be sent to the user application.
 func decodeOutgoingHttp(requestBuffer []byte, clienConn, destConn net.Conn, h *hooks.Hook, logger *zap.Logger) {
-	//Matching algorithmm
-	//Get the mocks
-	tcsMocks := h.GetTcsMocks()
-	var bestMatch *models.Mock
 	//Check if the expected header is present
 	if bytes.Contains(requestBuffer, []byte("Expect: 100-continue")) {
 		//Send the 100 continue response
@@ -380,69 +375,36 @@ func decodeOutgoingHttp(requestBuffer []byte, clienConn, destConn net.Conn, h *h
 	//check if req body is a json
 	isReqBodyJSON := isJSON(reqbody)
 
-	var eligibleMock []*models.Mock
-
-	for _, mock := range tcsMocks {
-		if mock.Kind == models.HTTP {
-			isMockBodyJSON := isJSON([]byte(mock.Spec.HttpReq.Body))
-
-			//the body of mock and request aren't of same type
-			if isMockBodyJSON != isReqBodyJSON {
-				continue
-			}
-
-			//parse request body url
-			parsedURL, err := url.Parse(mock.Spec.HttpReq.URL)
-			if err != nil {
-				logger.Error("failed to parse mock url", zap.Error(err))
-				continue
-			}
-
-			//Check if the path matches
-			if parsedURL.Path != reqURL.Path {
-				//If it is not the same, continue
-				continue
-			}
+	// the request is reported along with the matched mock to verify the dependencies
+	actualReq := fmt.Sprintf("%s http://%s%s\n%s", req.Method, req.Host, req.URL.RequestURI(), reqbody)
 
-			//Check if the method matches
-			if mock.Spec.HttpReq.Method != models.Method(req.Method) {
-				//If it is not the same, continue
-				continue
-			}
-
-			// Check if the header keys match
-			if !mapsHaveSameKeys(mock.Spec.HttpReq.Header, req.Header) {
-				// Different headers, so not a match
-				continue
-			}
-
-			if !mapsHaveSameKeys(mock.Spec.HttpReq.URLParams, req.URL.Query()) {
-				// Different query params, so not a match
-				continue
-			}
-			eligibleMock = append(eligibleMock, mock)
+	//Matching algorithmm
+	var bestMatch *models.Mock
+	for {
+		//Get the mocks
+		eligibleMock := eligibleMocks(h.GetTcsMocks(), req, reqURL, isReqBodyJSON, logger)
+		if len(eligibleMock) == 0 {
+			logger.Error( "Didn't match any prexisting http mock")
+			h.AddDepCall(models.HTTP, nil, actualReq)
+			util.Passthrough(clienConn, destConn, [][]byte{requestBuffer}, h.Recover, logger)
+			return
 		}
-	}
-
-	if len(eligibleMock) == 0 {
-		logger.Error( "Didn't match any prexisting http mock")
-		util.Passthrough(clienConn, destConn, [][]byte{requestBuffer}, h.Recover, logger)
-		return
-	}
 
-	_, bestMatch = util.Fuzzymatch(eligibleMock, requestBuffer, h)
+		var isMatched bool
+		isMatched, bestMatch = util.Fuzzymatch(eligibleMock, requestBuffer, h)
+		if !isMatched {
+			logger.Error("Didn't match any prexisting http mock")
+			h.AddDepCall(models.HTTP, nil, actualReq)
+			util.Passthrough(clienConn, destConn, [][]byte{requestBuffer}, h.Recover, logger)
+			return
+		}
 
-	var bestMatchIndex int
-	for idx, mock := range tcsMocks {
-		if reflect.DeepEqual(mock, bestMatch) {
-			bestMatchIndex = idx
+		// the mock is consumed before responding, so that a concurrent call doesn't respond
+		// with it as well. It has already been consumed when it isn't found, so match again.
+		if h.DeleteTcsMock(bestMatch) {
 			break
 		}
 	}
-	if h.GetDepsSize() == 0 {
-		// logger.Error("failed to mock the output for unrecorded outgoing http call")
-		return
-	}
 
 	// var httpSpec spec.HttpSpec
 	// err := deps[0].Spec.Decode(&httpSpec)
@@ -451,7 +413,7 @@ func decodeOutgoingHttp(requestBuffer []byte, clienConn, destConn net.Conn, h *h
 	// 	return
 	// }
 	// httpSpec := deps[0]
-	stub := h.FetchDep(bestMatchIndex)
+	stub := bestMatch
 	// fmt.Println("http mock in test: ", stub)
 
 	statusLine := fmt.Sprintf("HTTP/%d.%d %d %s\r\n", stub.Spec.HttpReq.ProtoMajor, stub.Spec.HttpReq.ProtoMinor, stub.Spec.HttpResp.StatusCode, http.StatusText(int(stub.Spec.HttpResp.StatusCode)))
@@ -501,12 +463,58 @@ func decodeOutgoingHttp(requestBuffer []byte, clienConn, destConn net.Conn, h *h
 		logger.Error("failed to write the mock output to the user application", zap.Error(err))
 		return
 	}
-	// pop the mocked output from the dependency queue
-	// deps = deps[1:]
-	h.PopIndex(bestMatchIndex)
+	h.AddDepCall(models.HTTP, bestMatch, actualReq)
 	return
 }
 
+// eligibleMocks returns the http mocks with the same path, method, header keys and query keys
+// as the request.
+func eligibleMocks(tcsMocks []*models.Mock, req *http.Request, reqURL *url.URL, isReqBodyJSON bool, logger *zap.Logger) []*models.Mock {
+	var eligibleMock []*models.Mock
+	for _, mock := range tcsMocks {
+		if mock.Kind == models.HTTP {
+			isMockBodyJSON := isJSON([]byte(mock.Spec.HttpReq.Body))
+
+			//the body of mock and request aren't of same type
+			if isMockBodyJSON != isReqBodyJSON {
+				continue
+			}
+
+			//parse request body url
+			parsedURL, err := url.Parse(mock.Spec.HttpReq.URL)
+			if err != nil {
+				logger.Error("failed to parse mock url", zap.Error(err))
+				continue
+			}
+
+			//Check if the path matches
+			if parsedURL.Path != reqURL.Path {
+				//If it is not the same, continue
+				continue
+			}
+
+			//Check if the method matches
+			if mock.Spec.HttpReq.Method != models.Method(req.Method) {
+				//If it is not the same, continue
+				continue
+			}
+
+			// Check if the header keys match
+			if !mapsHaveSameKeys(mock.Spec.HttpReq.Header, req.Header) {
+				// Different headers, so not a match
+				continue
+			}
+
+			if !mapsHaveSameKeys(mock.Spec.HttpReq.URLParams, req.URL.Query()) {
+				// Different query params, so not a match
+				continue
+			}
+			eligibleMock = append(eligibleMock, mock)
+		}
+	}
+	return eligibleMock
+}
+
 // encodeOutgoingHttp function parses the HTTP request and response text messages to capture outgoing network calls as mocks.
 func encodeOutgoingHttp(request []byte, clientConn, destConn net.Conn, logger *zap.Logger) (*models.Mock, error) {
 	defer destConn.Close()
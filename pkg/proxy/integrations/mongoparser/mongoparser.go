@@ -245,38 +245,18 @@ func decodeOutgoingMongo(clientConnId, destConnId int64, requestBuffer []byte, c
 				}
 			}
 		} else {
-			maxMatchScore := 0.0
 			bestMatchIndex := -1
-			for tcsIndx, tcsMock := range tcsMocks {
-				if len(tcsMock.Spec.MongoRequests) == len(mongoRequests) {
-					for i, req := range tcsMock.Spec.MongoRequests {
-						if len(tcsMock.Spec.MongoRequests) != len(mongoRequests) || req.Header.Opcode != mongoRequests[i].Header.Opcode {
-							continue
-						}
-						switch req.Header.Opcode {
-						case wiremessage.OpMsg:
-							if req.Message.(*models.MongoOpMessage).FlagBits != mongoRequests[i].Message.(*models.MongoOpMessage).FlagBits {
-								continue
-							}
-							scoreSum := 0.0
-							for sectionIndx, section := range req.Message.(*models.MongoOpMessage).Sections {
-								if len(req.Message.(*models.MongoOpMessage).Sections) == len(mongoRequests[i].Message.(*models.MongoOpMessage).Sections) {
-									score := compareOpMsgSection(section, mongoRequests[i].Message.(*models.MongoOpMessage).Sections[sectionIndx], logger)
-									scoreSum += score
-								}
-							}
-							currentScore := scoreSum / float64(len(mongoRequests))
-							if currentScore > maxMatchScore {
-								maxMatchScore = currentScore
-								bestMatchIndex = tcsIndx
-							}
-						default:
-							logger.Error("the OpCode of the mongo wiremessage is invalid.")
-						}
-					}
+			for {
+				bestMatchIndex = bestMongoMatch(tcsMocks, mongoRequests, logger)
+				// the mock is consumed before responding, so that a concurrent call doesn't respond
+				// with it as well. It has already been consumed when it isn't found, so match again.
+				if bestMatchIndex == -1 || h.DeleteTcsMock(tcsMocks[bestMatchIndex]) {
+					break
 				}
+				tcsMocks = h.GetTcsMocks()
 			}
 			if bestMatchIndex == -1 {
+				h.AddDepCall(models.Mongo, nil, mongoRequestsString(mongoRequests))
 				requestBuffer, err = util.Passthrough(clientConn, destConn, requestBuffers, h.Recover, logger)
 				if err != nil {
 					return
@@ -308,9 +288,9 @@ func decodeOutgoingMongo(clientConnId, destConnId int64, requestBuffer []byte, c
 				responseTo = requestId
 			}
 			logger.Debug(fmt.Sprintf("the length of tcsMocks before filtering matched: %v\n", len(tcsMocks)))
-			if maxMatchScore > 0.0 && bestMatchIndex >= 0 && bestMatchIndex < len(tcsMocks) {
-				tcsMocks = append(tcsMocks[:bestMatchIndex], tcsMocks[bestMatchIndex+1:]...)
-				h.SetTcsMocks(tcsMocks)
+			if bestMatchIndex >= 0 && bestMatchIndex < len(tcsMocks) {
+				h.AddDepCall(models.Mongo, tcsMocks[bestMatchIndex], mongoRequestsString(mongoRequests))
+				tcsMocks = h.GetTcsMocks()
 			}
 			logger.Debug(fmt.Sprintf("the length of tcsMocks after filtering matched: %v\n", len(tcsMocks)))
 		}
@@ -322,6 +302,55 @@ func decodeOutgoingMongo(clientConnId, destConnId int64, requestBuffer []byte, c
 	}
 }
 
+// bestMongoMatch returns the index of the mock whose requests are the most similar to the
+// requests of the outgoing call, or -1 when none of the mocks match.
+func bestMongoMatch(tcsMocks []*models.Mock, mongoRequests []models.MongoRequest, logger *zap.Logger) int {
+	maxMatchScore := 0.0
+	bestMatchIndex := -1
+	for tcsIndx, tcsMock := range tcsMocks {
+		if len(tcsMock.Spec.MongoRequests) == len(mongoRequests) {
+			for i, req := range tcsMock.Spec.MongoRequests {
+				if len(tcsMock.Spec.MongoRequests) != len(mongoRequests) || req.Header.Opcode != mongoRequests[i].Header.Opcode {
+					continue
+				}
+				switch req.Header.Opcode {
+				case wiremessage.OpMsg:
+					if req.Message.(*models.MongoOpMessage).FlagBits != mongoRequests[i].Message.(*models.MongoOpMessage).FlagBits {
+						continue
+					}
+					scoreSum := 0.0
+					for sectionIndx, section := range req.Message.(*models.MongoOpMessage).Sections {
+						if len(req.Message.(*models.MongoOpMessage).Sections) == len(mongoRequests[i].Message.(*models.MongoOpMessage).Sections) {
+							score := compareOpMsgSection(section, mongoRequests[i].Message.(*models.MongoOpMessage).Sections[sectionIndx], logger)
+							scoreSum += score
+						}
+					}
+					currentScore := scoreSum / float64(len(mongoRequests))
+					if currentScore > maxMatchScore {
+						maxMatchScore = currentScore
+						bestMatchIndex = tcsIndx
+					}
+				default:
+					logger.Error("the OpCode of the mongo wiremessage is invalid.")
+				}
+			}
+		}
+	}
+	return bestMatchIndex
+}
+
+// mongoRequestsString renders the requests of an outgoing call in the same way as the
+// requests of the recorded mocks.
+func mongoRequestsString(mongoRequests []models.MongoRequest) string {
+	mock := &models.Mock{
+		Kind: models.Mongo,
+		Spec: models.MockSpec{
+			MongoRequests: mongoRequests,
+		},
+	}
+	return mock.RequestString()
+}
+
 // func encodeOutgoingMongo(requestBuffer []byte, clientConn, destConn net.Conn, h *hooks.Hook, logger *zap.Logger) []*models.Mock {
 func encodeOutgoingMongo(clientConnId, destConnId int64, requestBuffer []byte, clientConn, destConn net.Conn, h *hooks.Hook, started time.Time, readRequestDelay time.Duration, logger *zap.Logger) {
 	rand.Seed(time.Now().UnixNano())
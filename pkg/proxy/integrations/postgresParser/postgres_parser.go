@@ -260,6 +260,7 @@ func decodePostgresOutgoing(requestBuffer []byte, clientConn, destConn net.Conn,
 
 		if !matched {
 			logger.Error("failed to match the dependency call from user application", zap.Any("request packets", len(pgRequests)))
+			h.AddDepCall(models.Postgres, nil, encodeRequests(pgRequests))
 			return errors.New("failed to match the dependency call from user application")
 			// continue
 		}
@@ -7,9 +7,11 @@ import (
 
 	"encoding/binary"
 	"errors"
+	"strings"
 	"unicode"
 
 	"github.com/agnivade/levenshtein"
+	"go.keploy.io/server/pkg"
 	"go.keploy.io/server/pkg/hooks"
 	"go.keploy.io/server/pkg/models"
 	"go.uber.org/zap"
@@ -57,6 +59,15 @@ func PostgresEncoder(buffer []byte) string {
 	return encoded
 }
 
+// encodeRequests encodes the request packets of an outgoing call, one packet per line.
+func encodeRequests(requestBuffers [][]byte) string {
+	encoded := make([]string, len(requestBuffers))
+	for i, reqBuff := range requestBuffers {
+		encoded[i] = PostgresEncoder(reqBuff)
+	}
+	return strings.Join(encoded, "\n")
+}
+
 func IdentifyPacket(data []byte) (models.Packet, error) {
 	// At least 4 bytes are required to determine the length
 	if len(data) < 4 {
@@ -123,15 +134,27 @@ func IsAsciiPrintable(s string) bool {
 }
 
 func Fuzzymatch(configMocks, tcsMocks []*models.Mock, reqBuff []byte, h *hooks.Hook) (bool, string) {
+	for {
+		mock := fuzzymatch(tcsMocks, reqBuff, h)
+		if mock == nil {
+			return false, ""
+		}
+		// the mock has already been consumed by a concurrent call when it isn't found, so match again
+		if h.DeleteTcsMock(mock) {
+			return true, mock.Spec.PostgresResp.Payload
+		}
+		tcsMocks = h.GetTcsMocks()
+	}
+}
+
+func fuzzymatch(tcsMocks []*models.Mock, reqBuff []byte, h *hooks.Hook) *models.Mock {
 	com := PostgresEncoder(reqBuff)
-	for idx, mock := range tcsMocks {
+	for _, mock := range tcsMocks {
 		encoded, _ := PostgresDecoder(mock.Spec.PostgresReq.Payload)
 
-		if string(encoded) == string(reqBuff) || mock.Spec.PostgresReq.Payload == com {
+		if string(encoded) == string(reqBuff) || mock.Spec.PostgresReq.Payload == com || matchRedacted(encoded, reqBuff) {
 			// fmt.Println("matched in first loop")
-			tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-			h.SetTcsMocks(tcsMocks)
-			return true, mock.Spec.PostgresResp.Payload
+			return mock
 		}
 	}
 	// convert all the configmocks to string array
@@ -144,21 +167,15 @@ func Fuzzymatch(configMocks, tcsMocks []*models.Mock, reqBuff []byte, h *hooks.H
 		// fmt.Println("Inside String Match")
 		idx := findStringMatch(string(reqBuff), mockString)
 		if idx != -1 {
-			nMatch := tcsMocks[idx].Spec.PostgresResp.Payload
-			tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-			h.SetTcsMocks(tcsMocks)
 			// fmt.Println("Returning mock from String Match !!")
-			return true, nMatch
+			return tcsMocks[idx]
 		}
 	}
 	idx := findBinaryMatch(tcsMocks, reqBuff, h)
 	if idx != -1 {
-		nMatch := tcsMocks[idx].Spec.PostgresResp.Payload
-		tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-		h.SetTcsMocks(tcsMocks)
-		return true, nMatch
+		return tcsMocks[idx]
 	}
-	return false, ""
+	return nil
 }
 
 func AdaptiveK(length, kMin, kMax, N int) int {
@@ -172,8 +189,23 @@ func AdaptiveK(length, kMin, kMax, N int) int {
 }
 
 func matchingPg(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook) (bool, []models.GenericPayload) {
+	for {
+		mock := matchPg(tcsMocks, requestBuffers, h)
+		if mock == nil {
+			return false, nil
+		}
+		// the mock has already been consumed by a concurrent call when it isn't found, so match again
+		if h.DeleteTcsMock(mock) {
+			h.AddDepCall(models.Postgres, mock, encodeRequests(requestBuffers))
+			return true, mock.Spec.GenericResponses
+		}
+		tcsMocks = h.GetTcsMocks()
+	}
+}
 
-	for idx, mock := range tcsMocks {
+func matchPg(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook) *models.Mock {
+
+	for _, mock := range tcsMocks {
 		if mock == nil {
 			continue
 		}
@@ -183,11 +215,9 @@ func matchingPg(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook)
 				bufStr := base64.StdEncoding.EncodeToString(reqBuff)
 				encoded, _ := PostgresDecoder(mock.Spec.GenericRequests[requestIndex].Message[0].Data)
 
-				if string(encoded) == string(reqBuff) || mock.Spec.GenericRequests[requestIndex].Message[0].Data == bufStr {
+				if string(encoded) == string(reqBuff) || mock.Spec.GenericRequests[requestIndex].Message[0].Data == bufStr || matchRedacted(encoded, reqBuff) {
 					// fmt.Println("matched in first loop")
-					tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-					h.SetTcsMocks(tcsMocks)
-					return true, mock.Spec.GenericResponses
+					return mock
 				}
 			}
 		}
@@ -196,13 +226,20 @@ func matchingPg(tcsMocks []*models.Mock, requestBuffers [][]byte, h *hooks.Hook)
 	idx := findBinaryStreamMatch(tcsMocks, requestBuffers, h)
 	if idx != -1 {
 		// fmt.Println("matched in first loop")
-		bestMatch := tcsMocks[idx].Spec.GenericResponses
 		// println("Lenght of tcsMocks", len(tcsMocks), " BestMatch -->", tcsMocks[idx].Spec.GenericRequests[0].Message[0].Data)
-		tcsMocks = append(tcsMocks[:idx], tcsMocks[idx+1:]...)
-		h.SetTcsMocks(tcsMocks)
-		return true, bestMatch
+		return tcsMocks[idx]
+	}
+	return nil
+}
+
+// matchRedacted reports whether the request matches the recorded request with redacted secrets.
+// A password message with a redacted password is recorded with the length of its placeholder,
+// so the lengths of the password messages are skipped.
+func matchRedacted(recorded, actual []byte) bool {
+	if len(recorded) > 5 && len(actual) > 5 && recorded[0] == 'p' && actual[0] == 'p' {
+		recorded, actual = recorded[5:], actual[5:]
 	}
-	return false, nil
+	return pkg.MatchRedacted(recorded, actual)
 }
 
 func findBinaryMatch(configMocks []*models.Mock, reqBuff []byte, h *hooks.Hook) int {
@@ -306,5 +343,4 @@ func ChangeAuthToMD5(tcsMocks []*models.Mock, h *hooks.Hook, log *zap.Logger) {
 			}
 		}
 	}
-	h.SetTcsMocks(tcsMocks)
 }
@@ -0,0 +1,38 @@
+package postgresparser
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"go.keploy.io/server/pkg"
+)
+
+func TestMatchRedacted(t *testing.T) {
+	message := func(id byte, content string) []byte {
+		msg := []byte{id, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(msg[1:5], uint32(4+len(content)))
+		return append(msg, content...)
+	}
+	placeholder := pkg.RedactedPlaceholder("s3cret", "")
+
+	tests := []struct {
+		name     string
+		recorded []byte
+		actual   []byte
+		want     bool
+	}{
+		{name: "redacted password", recorded: message('p', placeholder+"\x00"), actual: message('p', "s3cret\x00"), want: true},
+		{name: "redacted password of another length", recorded: message('p', placeholder+"\x00"), actual: message('p', "a-much-longer-password\x00"), want: true},
+		{name: "password without placeholder", recorded: message('p', "s3cret\x00"), actual: message('p', "other!\x00"), want: false},
+		{name: "other message type", recorded: message('p', placeholder+"\x00"), actual: message('Q', "s3cret\x00"), want: false},
+		{name: "query with the length of its placeholder", recorded: message('Q', "select '"+placeholder+"'\x00"), actual: message('Q', "select 'abc'\x00"), want: false},
+		{name: "truncated message", recorded: message('p', placeholder+"\x00"), actual: []byte{'p', 0}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRedacted(tt.recorded, tt.actual); got != tt.want {
+				t.Errorf("matchRedacted(%q, %q) = %v, want %v", tt.recorded, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
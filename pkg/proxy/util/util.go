@@ -2,6 +2,7 @@ package util
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -440,9 +441,11 @@ func HttpEncoder(buffer []byte) string {
 }
 func Fuzzymatch(tcsMocks []*models.Mock, reqBuff []byte, h *hooks.Hook) (bool, *models.Mock) {
 	com := HttpEncoder(reqBuff)
+	// the redacted secrets of the mocks are matched against the body of the request
+	_, reqBody, _ := bytes.Cut(reqBuff, []byte("\r\n\r\n"))
 	for _, mock := range tcsMocks {
 		encoded, _ := HttpDecoder(mock.Spec.HttpReq.Body)
-		if string(encoded) == string(reqBuff) || mock.Spec.HttpReq.Body == com {
+		if string(encoded) == string(reqBuff) || mock.Spec.HttpReq.Body == com || pkg.MatchRedacted(encoded, reqBody) {
 
 			log.Debug(Emoji, "matched in first loop")
 
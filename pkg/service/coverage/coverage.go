@@ -0,0 +1,150 @@
+package coverage
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+type reporter struct {
+	logger *zap.Logger
+}
+
+func NewReporter(logger *zap.Logger) Reporter {
+	return &reporter{
+		logger: logger,
+	}
+}
+
+// endpoint is a route of the application exercised by the recorded testcases.
+type endpoint struct {
+	method   string
+	template string
+	tests    int
+	statuses map[int]int
+	// paths are the recorded paths of the route, used to match the operations of the spec
+	paths []string
+}
+
+func (r *reporter) Report(path string, openAPIPath string) error {
+	testSets, err := yaml.ReadSessionIndices(path, r.logger)
+	if err != nil {
+		r.logger.Error("failed to read the recorded test sets", zap.Error(err))
+		return err
+	}
+
+	ys := yaml.NewYamlStore(path+"/tests", path, "", "", r.logger)
+	endpoints := map[string]*endpoint{}
+	for _, testSet := range testSets {
+		tcs, err := ys.ReadTestcase(filepath.Join(path, testSet, "tests"), nil)
+		if err != nil {
+			return err
+		}
+		for _, tc := range tcs {
+			method, reqPath, status, ok := route(tc)
+			if !ok {
+				continue
+			}
+			template := pkg.PathTemplate(reqPath)
+			key := method + " " + template
+			e, ok := endpoints[key]
+			if !ok {
+				e = &endpoint{method: method, template: template, statuses: map[int]int{}}
+				endpoints[key] = e
+			}
+			e.tests++
+			e.statuses[status]++
+			e.paths = append(e.paths, reqPath)
+		}
+	}
+
+	sorted := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].template != sorted[j].template {
+			return sorted[i].template < sorted[j].template
+		}
+		return sorted[i].method < sorted[j].method
+	})
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Method", "Endpoint", "Tests", "Status codes"})
+	table.SetAutoWrapText(false)
+	for _, e := range sorted {
+		table.Append([]string{e.method, e.template, strconv.Itoa(e.tests), statusCodes(e.statuses)})
+	}
+	fmt.Printf("%s endpoints exercised by the recorded testcases in %s\n", Emoji, path)
+	table.Render()
+
+	if openAPIPath == "" {
+		return nil
+	}
+
+	spec, err := readOpenAPI(openAPIPath)
+	if err != nil {
+		r.logger.Error("failed to read the OpenAPI spec", zap.Error(err), zap.Any("path", openAPIPath))
+		return err
+	}
+
+	ops := spec.operations()
+	untested := [][]string{}
+	for _, op := range ops {
+		if !op.testedBy(sorted, spec.basePaths()) {
+			untested = append(untested, []string{op.method, op.path, op.operationID})
+		}
+	}
+
+	fmt.Printf("%s %d of %d operations of the OpenAPI spec are tested\n", Emoji, len(ops)-len(untested), len(ops))
+	if len(untested) == 0 {
+		return nil
+	}
+	table = tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Method", "Untested operation", "Operation id"})
+	table.SetAutoWrapText(false)
+	table.AppendBulk(untested)
+	table.Render()
+	return nil
+}
+
+// route returns the method, the path and the status code of the recorded call of the testcase.
+func route(tc *models.TestCase) (string, string, int, bool) {
+	switch tc.Kind {
+	case models.HTTP:
+		u, err := url.Parse(tc.HttpReq.URL)
+		if err != nil {
+			return "", "", 0, false
+		}
+		return string(tc.HttpReq.Method), u.Path, tc.HttpResp.StatusCode, true
+	case models.GRPC_EXPORT:
+		status, _ := strconv.Atoi(tc.GrpcResp.Headers.PseudoHeaders[":status"])
+		return "gRPC", tc.GrpcReq.Headers.PseudoHeaders[":path"], status, true
+	}
+	return "", "", 0, false
+}
+
+func statusCodes(statuses map[int]int) string {
+	codes := make([]int, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	res := make([]string, len(codes))
+	for i, code := range codes {
+		res[i] = fmt.Sprintf("%d (%d)", code, statuses[code])
+	}
+	return strings.Join(res, ", ")
+}
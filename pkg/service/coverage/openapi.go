@@ -0,0 +1,123 @@
+package coverage
+
+import (
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPISpec holds the parts of an OpenAPI (v3) or Swagger (v2) spec needed to find the
+// operations of the application. The JSON specs are read as well, since JSON is valid YAML.
+type openAPISpec struct {
+	BasePath string `yaml:"basePath"`
+	Servers  []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]map[string]yamlLib.Node `yaml:"paths"`
+}
+
+type operation struct {
+	method      string
+	path        string
+	operationID string
+}
+
+func readOpenAPI(path string) (*openAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &openAPISpec{}
+	err = yamlLib.Unmarshal(data, spec)
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// operations returns the operations of the spec sorted by their paths.
+func (s *openAPISpec) operations() []operation {
+	ops := []operation{}
+	for path, item := range s.Paths {
+		for _, method := range httpMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			op := struct {
+				OperationID string `yaml:"operationId"`
+			}{}
+			// the operation id is only used to describe the operation
+			_ = node.Decode(&op)
+			ops = append(ops, operation{
+				method:      strings.ToUpper(method),
+				path:        path,
+				operationID: op.OperationID,
+			})
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+	return ops
+}
+
+// basePaths returns the prefixes of the paths of the operations, declared by the servers of
+// the spec.
+func (s *openAPISpec) basePaths() []string {
+	prefixes := []string{""}
+	if s.BasePath != "" && s.BasePath != "/" {
+		prefixes = append(prefixes, strings.TrimSuffix(s.BasePath, "/"))
+	}
+	for _, server := range s.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil || u.Path == "" || u.Path == "/" {
+			continue
+		}
+		prefixes = append(prefixes, strings.TrimSuffix(u.Path, "/"))
+	}
+	return prefixes
+}
+
+// testedBy checks whether any of the recorded paths of the endpoints matches the operation.
+// The templated segments of the operation, e.g. {userId}, match any segment.
+func (op operation) testedBy(endpoints []*endpoint, basePaths []string) bool {
+	for _, e := range endpoints {
+		if e.method != op.method {
+			continue
+		}
+		for _, p := range e.paths {
+			for _, base := range basePaths {
+				if matchPath(base+op.path, p) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func matchPath(template, path string) bool {
+	tSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(tSegments) != len(pSegments) {
+		return false
+	}
+	for i, segment := range tSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment != pSegments[i] {
+			return false
+		}
+	}
+	return true
+}
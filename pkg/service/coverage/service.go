@@ -0,0 +1,7 @@
+package coverage
+
+type Reporter interface {
+	// Report prints the endpoints exercised by the recorded testcases, and the operations of
+	// the OpenAPI spec which are not tested when the path of the spec is provided.
+	Report(path string, openAPIPath string) error
+}
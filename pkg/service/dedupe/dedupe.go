@@ -0,0 +1,164 @@
+package dedupe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+type deduper struct {
+	logger *zap.Logger
+}
+
+func NewDeduper(logger *zap.Logger) Deduper {
+	return &deduper{
+		logger: logger,
+	}
+}
+
+// duplicate is a testcase with the same signature as an earlier testcase of its test set.
+type duplicate struct {
+	testSet string
+	name    string
+	of      string
+}
+
+// dedupedSet holds the duplicate testcases of a test set, and the number of mocks which were
+// captured during the duplicates only.
+type dedupedSet struct {
+	name          string
+	duplicates    []duplicate
+	removed, kept []*models.TestCase
+	orphanMocks   int
+}
+
+func (d *deduper) Dedupe(path string, testSets []string, apply bool) error {
+	if len(testSets) == 0 {
+		sessions, err := yaml.ReadSessionIndices(path, d.logger)
+		if err != nil {
+			d.logger.Error("failed to read the test sets", zap.Error(err))
+			return err
+		}
+		testSets = sessions
+	}
+
+	var (
+		sets       []*dedupedSet
+		duplicates []duplicate
+	)
+	for _, testSet := range testSets {
+		set, err := d.findDuplicates(path, testSet)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, set)
+		duplicates = append(duplicates, set.duplicates...)
+	}
+
+	if len(duplicates) == 0 {
+		fmt.Printf("%s no duplicate testcases found\n", Emoji)
+		return nil
+	}
+	rows := make([][]string, len(duplicates))
+	for i, dup := range duplicates {
+		rows[i] = []string{dup.testSet, dup.name, dup.of}
+	}
+	if apply {
+		fmt.Printf("%s removing %d duplicate testcases\n", Emoji, len(duplicates))
+	} else {
+		fmt.Printf("%s found %d duplicate testcases, use --apply to remove them\n", Emoji, len(duplicates))
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Test Set", "Testcase", "Duplicate Of"})
+	table.SetAutoWrapText(false)
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.AppendBulk(rows)
+	table.Render()
+	for _, set := range sets {
+		if set.orphanMocks > 0 {
+			fmt.Printf("%s %d mocks of the test set %s were captured during the duplicate testcases only\n", Emoji, set.orphanMocks, set.name)
+		}
+	}
+
+	if !apply {
+		return nil
+	}
+	for _, set := range sets {
+		for _, dup := range set.duplicates {
+			err := yaml.DeleteTestcase(filepath.Join(path, dup.testSet, "tests"), dup.name, d.logger)
+			if err != nil {
+				return err
+			}
+		}
+		if set.orphanMocks > 0 {
+			if _, err := yaml.PruneMocks(filepath.Join(path, set.name), set.removed, set.kept, true, d.logger); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findDuplicates returns the testcases of the test set with the same signature as an earlier
+// recorded testcase, along with the mocks captured during them. The earliest testcase of every
+// signature is kept.
+func (d *deduper) findDuplicates(path, testSet string) (*dedupedSet, error) {
+	dir := filepath.Join(path, testSet, "tests")
+	if _, err := os.Stat(dir); err != nil {
+		d.logger.Error("failed to find the testcases of the test set", zap.Error(err), zap.Any("test-set", testSet))
+		return nil, err
+	}
+	ys := yaml.NewYamlStore(dir, filepath.Join(path, testSet), "", "", d.logger)
+	tcs, err := ys.ReadTestcase(dir, nil)
+	if err != nil {
+		d.logger.Error("failed to read the testcases of the test set", zap.Error(err), zap.Any("test-set", testSet))
+		return nil, err
+	}
+
+	// the testcases recorded in the same second are ordered by their index
+	sort.SliceStable(tcs, func(i, j int) bool {
+		if tcs[i].Created != tcs[j].Created {
+			return tcs[i].Created < tcs[j].Created
+		}
+		return index(tcs[i].Name) < index(tcs[j].Name)
+	})
+
+	set := &dedupedSet{name: testSet}
+	kept := map[string]string{}
+	for _, tc := range tcs {
+		signature := yaml.Signature(tc)
+		if name, ok := kept[signature]; ok && signature != "" {
+			set.duplicates = append(set.duplicates, duplicate{testSet: testSet, name: tc.Name, of: name})
+			set.removed = append(set.removed, tc)
+			continue
+		}
+		if signature != "" {
+			kept[signature] = tc.Name
+		}
+		set.kept = append(set.kept, tc)
+	}
+	set.orphanMocks, err = yaml.PruneMocks(filepath.Join(path, testSet), set.removed, set.kept, false, d.logger)
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// index returns the sequence number of the testcase, e.g. 12 for test-12.
+func index(name string) int {
+	i, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return 0
+	}
+	return i
+}
@@ -0,0 +1,8 @@
+package dedupe
+
+type Deduper interface {
+	// Dedupe finds the testcases of the test sets whose normalised request and response are the
+	// same as an earlier testcase of their test set, and prints them. The duplicates are removed
+	// when apply is set. All the test sets of the path are deduplicated when none are passed.
+	Dedupe(path string, testSets []string, apply bool) error
+}
@@ -0,0 +1,226 @@
+package denoise
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/hooks"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.keploy.io/server/pkg/proxy"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+type denoiser struct {
+	logger *zap.Logger
+}
+
+func NewDenoiser(logger *zap.Logger) Denoiser {
+	return &denoiser{
+		logger: logger,
+	}
+}
+
+func (d *denoiser) Denoise(path string, appCmd, appContainer, appNetwork string, Delay uint64, passThroughPorts []uint, apiTimeout uint64, runs uint) bool {
+	// the replays are served by the recorded mocks, as in a test run
+	models.SetMode(models.MODE_TEST)
+
+	if runs < 2 {
+		d.logger.Error("the testcases must be replayed at least twice to find the noisy fields", zap.Any("runs", runs))
+		return false
+	}
+
+	ys := yaml.NewYamlStore(path+"/tests", path, "", "", d.logger)
+
+	routineId := pkg.GenerateRandomID()
+	// Initiate the hooks
+	loadedHooks := hooks.NewHook(ys, routineId, d.logger)
+
+	// Recover from panic and gracfully shutdown
+	defer loadedHooks.Recover(routineId)
+
+	// load the ebpf hooks into the kernel
+	if err := loadedHooks.LoadHooks(appCmd, appContainer, 0); err != nil {
+		return false
+	}
+
+	// start the proxy
+	ps := proxy.BootProxy(d.logger, proxy.Option{}, appCmd, appContainer, 0, "", passThroughPorts, loadedHooks)
+
+	//Sending Proxy Ip & Port to the ebpf program
+	if err := loadedHooks.SendProxyInfo(ps.IP4, ps.Port, ps.IP6); err != nil {
+		return false
+	}
+
+	sessions, err := yaml.ReadSessionIndices(path, d.logger)
+	if err != nil {
+		d.logger.Debug("failed to read the recorded sessions", zap.Error(err))
+		return false
+	}
+
+	result := true
+	for _, sessionIndex := range sessions {
+		result = d.denoiseTestSet(sessionIndex, path, appCmd, appContainer, appNetwork, Delay, ys, loadedHooks, apiTimeout, runs) && result
+	}
+	d.logger.Info("denoising completed", zap.Bool("success", result))
+
+	// stop listening for the eBPF events
+	loadedHooks.Stop(true)
+
+	//stop listening for proxy server
+	ps.StopProxyServer()
+
+	return result
+}
+
+func (d *denoiser) denoiseTestSet(testSet, path, appCmd, appContainer, appNetwork string, delay uint64, ys platform.TestCaseDB, loadedHooks *hooks.Hook, apiTimeout uint64, runs uint) bool {
+	// Recover from panic and gracfully shutdown
+	defer loadedHooks.Recover(pkg.GenerateRandomID())
+
+	tcsPath := filepath.Join(path, testSet, "tests")
+	tcs, err := ys.ReadTestcase(tcsPath, nil)
+	if err != nil || len(tcs) == 0 {
+		return true
+	}
+
+	configMocks, tcsMocks, err := ys.ReadMocks(filepath.Join(path, testSet))
+	if err != nil {
+		return false
+	}
+	loadedHooks.SetConfigMocks(configMocks)
+
+	if err := loadedHooks.LaunchUserApplication(appCmd, appContainer, appNetwork, delay); err != nil {
+		d.logger.Debug("failed to process the user application")
+		return false
+	}
+	defer loadedHooks.StopUserApplication()
+
+	var userIp string
+	ok, _ := loadedHooks.IsDockerRelatedCmd(appCmd)
+	isDocker := ok || (appCmd == "" && len(appContainer) != 0)
+	if isDocker {
+		userIp = loadedHooks.GetUserIP()
+	}
+
+	// added delay to hold the replays until application starts
+	time.Sleep(time.Duration(delay) * time.Second)
+
+	// the testcases are rewritten in the directory of the test set
+	tcsDB := yaml.NewYamlStore(tcsPath, filepath.Join(path, testSet), "", "", d.logger)
+	// vars stores the values extracted from the responses of the earlier testcases
+	vars := map[string]string{}
+	for _, tc := range tcs {
+		if tc.Kind != models.HTTP {
+			d.logger.Info("skipping the testcase since only the http testcases can be denoised", zap.Any("testcase id", tc.Name), zap.Any("kind", tc.Kind))
+			continue
+		}
+
+		simTc := *tc
+		if isDocker {
+			replaced, err := pkg.ReplaceHostToIP(tc.HttpReq.URL, userIp)
+			if err != nil {
+				d.logger.Warn("failed to replace the host of the url with the ip of the user container, keeping the url as it is", zap.Any("url", tc.HttpReq.URL), zap.Error(err))
+			}
+			simTc.HttpReq.URL = replaced
+		}
+
+		var responses []map[string][]string
+		for i := uint(0); i < runs; i++ {
+			// every replay consumes the mocks, so the mocks are loaded again for each of them
+			loadedHooks.SetTcsMocks(append([]*models.Mock{}, tcsMocks...))
+			resp, err := pkg.SimulateHttp(simTc, vars, d.logger, apiTimeout)
+			if err != nil {
+				d.logger.Error("failed to replay the testcase", zap.Error(err), zap.Any("testcase id", tc.Name))
+				break
+			}
+			if i == 0 && len(tc.Extract) > 0 {
+				err = pkg.ExtractVars(tc.Extract, resp, vars)
+				if err != nil {
+					d.logger.Warn("failed to extract the values from the response of the testcase", zap.Error(err), zap.Any("testcase id", tc.Name))
+				}
+			}
+			m, err := yaml.FlattenHttpResponse(pkg.ToHttpHeader(resp.Header), resp.Body)
+			if err != nil {
+				d.logger.Error("error in flattening http response", zap.Error(err))
+				break
+			}
+			responses = append(responses, m)
+		}
+		if len(responses) < 2 {
+			continue
+		}
+
+		// the fields already marked as noise are not added again
+		noise := []string{}
+		for _, field := range changedFields(responses) {
+			if !contains(tc.Noise, field) {
+				noise = append(noise, field)
+			}
+		}
+		if len(noise) == 0 {
+			d.logger.Info("no new noisy fields found in the testcase", zap.Any("testcase id", tc.Name))
+			continue
+		}
+		d.logger.Info("found noisy fields in the testcase", zap.Any("testcase id", tc.Name), zap.Any("noise", noise))
+
+		tc.Noise = append(tc.Noise, noise...)
+		err := tcsDB.WriteTestcase(tc)
+		if err != nil {
+			d.logger.Error("failed to write the noise of the testcase", zap.Error(err), zap.Any("testcase id", tc.Name))
+			return false
+		}
+	}
+	return true
+}
+
+// changedFields returns the flattened fields whose values are not the same in every response,
+// along with the fields which are missing from some of the responses. The values of the
+// arrays are compared irrespective of their order.
+func changedFields(responses []map[string][]string) []string {
+	keys := map[string]bool{}
+	for _, m := range responses {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+
+	noise := []string{}
+	for k := range keys {
+		first, ok := responses[0][k]
+		if !ok {
+			noise = append(noise, k)
+			continue
+		}
+		first = sortedCopy(first)
+		for _, m := range responses[1:] {
+			vals, ok := m[k]
+			if !ok || !reflect.DeepEqual(first, sortedCopy(vals)) {
+				noise = append(noise, k)
+				break
+			}
+		}
+	}
+	sort.Strings(noise)
+	return noise
+}
+
+func sortedCopy(vals []string) []string {
+	res := append([]string{}, vals...)
+	sort.Strings(res)
+	return res
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package denoise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []map[string][]string
+		want      []string
+	}{
+		{
+			name: "identical responses",
+			responses: []map[string][]string{
+				{"body.id": {"1"}, "header.Content-Type": {"application/json"}},
+				{"body.id": {"1"}, "header.Content-Type": {"application/json"}},
+			},
+			want: []string{},
+		},
+		{
+			name: "changed values",
+			responses: []map[string][]string{
+				{"body.id": {"1"}, "header.Date": {"Mon"}, "body.name": {"a"}},
+				{"body.id": {"1"}, "header.Date": {"Tue"}, "body.name": {"a"}},
+			},
+			want: []string{"header.Date"},
+		},
+		{
+			name: "fields missing from some of the responses",
+			responses: []map[string][]string{
+				{"body.id": {"1"}, "body.etag": {"x"}},
+				{"body.id": {"1"}},
+				{"body.id": {"1"}, "body.trace": {"y"}},
+			},
+			want: []string{"body.etag", "body.trace"},
+		},
+		{
+			name: "reordered array values",
+			responses: []map[string][]string{
+				{"body.tags": {"a", "b"}},
+				{"body.tags": {"b", "a"}},
+			},
+			want: []string{},
+		},
+		{
+			name: "changed in the last response only",
+			responses: []map[string][]string{
+				{"body.count": {"1"}},
+				{"body.count": {"1"}},
+				{"body.count": {"2"}},
+			},
+			want: []string{"body.count"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedFields(tt.responses); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package denoise
+
+type Denoiser interface {
+	// Denoise replays every recorded testcase against the user application multiple times and
+	// adds the fields of the responses which change between the replays to the noise of the testcase.
+	Denoise(path string, appCmd, appContainer, networkName string, Delay uint64, passThroughPorts []uint, apiTimeout uint64, runs uint) bool
+}
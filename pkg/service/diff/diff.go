@@ -0,0 +1,388 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/wI2L/jsondiff"
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+type differ struct {
+	logger *zap.Logger
+}
+
+func NewDiffer(logger *zap.Logger) Differ {
+	return &differ{
+		logger: logger,
+	}
+}
+
+// Report is the API behaviour diff between two test sets.
+type Report struct {
+	SetA    string           `json:"setA"`
+	SetB    string           `json:"setB"`
+	Added   []string         `json:"added"`
+	Removed []string         `json:"removed"`
+	Changed []EndpointChange `json:"changed"`
+}
+
+// EndpointChange lists the changes in the responses of an endpoint recorded in both test sets.
+type EndpointChange struct {
+	Endpoint      string         `json:"endpoint"`
+	StatusCodes   []StatusChange `json:"statusCodes,omitempty"`
+	AddedFields   []string       `json:"addedFields,omitempty"`
+	RemovedFields []string       `json:"removedFields,omitempty"`
+	RetypedFields []FieldChange  `json:"retypedFields,omitempty"`
+}
+
+// StatusChange is the change in the status code between a pair of testcases.
+type StatusChange struct {
+	TestCaseA string `json:"testCaseA"`
+	TestCaseB string `json:"testCaseB"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+}
+
+// FieldChange is the change in the JSON type of a field of the response body.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// call is a recorded testcase with the endpoint and the normalised request used to pair it.
+type call struct {
+	tc       *models.TestCase
+	endpoint string
+	request  string
+	status   int
+	body     string
+}
+
+func (d *differ) Diff(path, setA, setB, format string) error {
+	if format != "text" && format != "json" {
+		d.logger.Error("unsupported diff format, use text or json", zap.Any("format", format))
+		return fmt.Errorf("unsupported diff format %s", format)
+	}
+	callsA, err := d.readCalls(path, setA)
+	if err != nil {
+		return err
+	}
+	callsB, err := d.readCalls(path, setB)
+	if err != nil {
+		return err
+	}
+
+	report := compare(callsA, callsB)
+	report.SetA, report.SetB = setA, setB
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			d.logger.Error("failed to marshal the diff", zap.Error(err))
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printReport(report)
+	return nil
+}
+
+// readCalls reads the testcases of the test set, grouped by their endpoints.
+func (d *differ) readCalls(path, testSet string) (map[string][]call, error) {
+	dir := filepath.Join(path, testSet, "tests")
+	if _, err := os.Stat(dir); err != nil {
+		d.logger.Error("failed to find the testcases of the test set", zap.Error(err), zap.Any("test-set", testSet))
+		return nil, err
+	}
+	ys := yaml.NewYamlStore(path+"/tests", path, "", "", d.logger)
+	tcs, err := ys.ReadTestcase(dir, nil)
+	if err != nil {
+		d.logger.Error("failed to read the testcases of the test set", zap.Error(err), zap.Any("test-set", testSet))
+		return nil, err
+	}
+	calls := map[string][]call{}
+	for _, tc := range tcs {
+		c, ok := newCall(tc)
+		if !ok {
+			continue
+		}
+		calls[c.endpoint] = append(calls[c.endpoint], c)
+	}
+	return calls, nil
+}
+
+func newCall(tc *models.TestCase) (call, bool) {
+	switch tc.Kind {
+	case models.HTTP:
+		u, err := url.Parse(tc.HttpReq.URL)
+		if err != nil {
+			return call{}, false
+		}
+		return call{
+			tc:       tc,
+			endpoint: string(tc.HttpReq.Method) + " " + pkg.PathTemplate(u.Path),
+			request:  u.Path + "?" + u.Query().Encode() + " " + normaliseJson(tc.HttpReq.Body),
+			status:   tc.HttpResp.StatusCode,
+			body:     tc.HttpResp.Body,
+		}, true
+	case models.GRPC_EXPORT:
+		path := tc.GrpcReq.Headers.PseudoHeaders[":path"]
+		status, _ := strconv.Atoi(tc.GrpcResp.Trailers.OrdinaryHeaders["grpc-status"])
+		return call{
+			tc:       tc,
+			endpoint: "gRPC " + path,
+			request:  tc.GrpcReq.Body.DecodedData,
+			status:   status,
+		}, true
+	}
+	return call{}, false
+}
+
+// normaliseJson re-encodes the JSON body with its keys sorted, so that the requests which
+// only differ in the order of their keys are equal.
+func normaliseJson(body string) string {
+	var v interface{}
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return body
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// compare pairs the testcases of the endpoints recorded in both test sets and diffs their
+// responses. The testcases with the same normalised request are paired first, and the rest of
+// them in their order.
+func compare(callsA, callsB map[string][]call) *Report {
+	report := &Report{Added: []string{}, Removed: []string{}, Changed: []EndpointChange{}}
+	for endpoint := range callsA {
+		if _, ok := callsB[endpoint]; !ok {
+			report.Removed = append(report.Removed, endpoint)
+		}
+	}
+	for endpoint := range callsB {
+		if _, ok := callsA[endpoint]; !ok {
+			report.Added = append(report.Added, endpoint)
+		}
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+
+	endpoints := []string{}
+	for endpoint := range callsA {
+		if _, ok := callsB[endpoint]; ok {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Strings(endpoints)
+
+	for _, endpoint := range endpoints {
+		change := newFieldChanges()
+		result := EndpointChange{Endpoint: endpoint}
+		for _, p := range pairCalls(callsA[endpoint], callsB[endpoint]) {
+			a, b := p[0], p[1]
+			if a.status != b.status {
+				result.StatusCodes = append(result.StatusCodes, StatusChange{
+					TestCaseA: a.tc.Name,
+					TestCaseB: b.tc.Name,
+					From:      a.status,
+					To:        b.status,
+				})
+			}
+			change.add(a.body, b.body)
+		}
+		result.AddedFields, result.RemovedFields, result.RetypedFields = change.sorted()
+		if len(result.StatusCodes) > 0 || len(result.AddedFields) > 0 || len(result.RemovedFields) > 0 || len(result.RetypedFields) > 0 {
+			report.Changed = append(report.Changed, result)
+		}
+	}
+	return report
+}
+
+func pairCalls(callsA, callsB []call) [][2]call {
+	var (
+		pairs   [][2]call
+		pairedB = make([]bool, len(callsB))
+		restA   []call
+	)
+	for _, a := range callsA {
+		matched := false
+		for j, b := range callsB {
+			if !pairedB[j] && a.request == b.request {
+				pairs = append(pairs, [2]call{a, b})
+				pairedB[j], matched = true, true
+				break
+			}
+		}
+		if !matched {
+			restA = append(restA, a)
+		}
+	}
+	for _, a := range restA {
+		for j, b := range callsB {
+			if !pairedB[j] {
+				pairs = append(pairs, [2]call{a, b})
+				pairedB[j] = true
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// fieldChanges collects the changes in the fields of the JSON responses of an endpoint.
+type fieldChanges struct {
+	added   map[string]bool
+	removed map[string]bool
+	retyped map[string]FieldChange
+}
+
+func newFieldChanges() *fieldChanges {
+	return &fieldChanges{added: map[string]bool{}, removed: map[string]bool{}, retyped: map[string]FieldChange{}}
+}
+
+// add diffs the fields of the JSON bodies of a pair of responses. The fields are the flattened
+// paths of the bodies, where the elements of the arrays share the path of the array.
+func (f *fieldChanges) add(bodyA, bodyB string) {
+	var a, b interface{}
+	if json.Unmarshal([]byte(bodyA), &a) != nil || json.Unmarshal([]byte(bodyB), &b) != nil {
+		return
+	}
+	fieldsA, fieldsB := yaml.Flatten(a), yaml.Flatten(b)
+	for field := range fieldsB {
+		if _, ok := fieldsA[field]; !ok && field != "" {
+			f.added[field] = true
+		}
+	}
+	for field := range fieldsA {
+		if _, ok := fieldsB[field]; !ok && field != "" {
+			f.removed[field] = true
+		}
+	}
+
+	patch, err := jsondiff.Compare(a, b)
+	if err != nil {
+		return
+	}
+	for _, op := range patch {
+		if op.Type != jsondiff.OperationReplace {
+			continue
+		}
+		from, to := jsonType(op.OldValue), jsonType(op.Value)
+		if from == to {
+			continue
+		}
+		field := fieldPath(op.Path)
+		f.retyped[field+" "+from+" "+to] = FieldChange{Field: field, From: from, To: to}
+	}
+}
+
+func (f *fieldChanges) sorted() ([]string, []string, []FieldChange) {
+	keys := func(m map[string]bool) []string {
+		res := []string{}
+		for k := range m {
+			res = append(res, k)
+		}
+		sort.Strings(res)
+		return res
+	}
+	retyped := []FieldChange{}
+	for _, c := range f.retyped {
+		retyped = append(retyped, c)
+	}
+	sort.Slice(retyped, func(i, j int) bool {
+		if retyped[i].Field != retyped[j].Field {
+			return retyped[i].Field < retyped[j].Field
+		}
+		return retyped[i].From+retyped[i].To < retyped[j].From+retyped[j].To
+	})
+	return keys(f.added), keys(f.removed), retyped
+}
+
+// fieldPath converts the JSON pointer of a field into its flattened path, by dropping the
+// indices of the arrays. e.g. /items/0/price becomes items.price.
+func fieldPath(pointer string) string {
+	var segments []string
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(segment); err == nil {
+			continue
+		}
+		segment = strings.ReplaceAll(segment, "~1", "/")
+		segments = append(segments, strings.ReplaceAll(segment, "~0", "~"))
+	}
+	return strings.Join(segments, ".")
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func printReport(report *Report) {
+	rows := [][]string{}
+	for _, endpoint := range report.Added {
+		rows = append(rows, []string{endpoint, "endpoint added", ""})
+	}
+	for _, endpoint := range report.Removed {
+		rows = append(rows, []string{endpoint, "endpoint removed", ""})
+	}
+	for _, c := range report.Changed {
+		for _, s := range c.StatusCodes {
+			rows = append(rows, []string{c.Endpoint, "status code", fmt.Sprintf("%d -> %d (%s -> %s)", s.From, s.To, s.TestCaseA, s.TestCaseB)})
+		}
+		for _, field := range c.AddedFields {
+			rows = append(rows, []string{c.Endpoint, "field added", field})
+		}
+		for _, field := range c.RemovedFields {
+			rows = append(rows, []string{c.Endpoint, "field removed", field})
+		}
+		for _, f := range c.RetypedFields {
+			rows = append(rows, []string{c.Endpoint, "field retyped", fmt.Sprintf("%s: %s -> %s", f.Field, f.From, f.To)})
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("%s no changes in the API behaviour between %s and %s\n", Emoji, report.SetA, report.SetB)
+		return
+	}
+	fmt.Printf("%s changes in the API behaviour from %s to %s\n", Emoji, report.SetA, report.SetB)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Endpoint", "Change", "Details"})
+	table.SetAutoWrapText(false)
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.AppendBulk(rows)
+	table.Render()
+}
@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestPairCalls(t *testing.T) {
+	newCall := func(name, request string) call {
+		return call{tc: &models.TestCase{Name: name}, request: request}
+	}
+
+	tests := []struct {
+		name   string
+		callsA []call
+		callsB []call
+		// want are the names of the paired testcases of the sets
+		want [][2]string
+	}{
+		{
+			name:   "identical requests are paired regardless of their order",
+			callsA: []call{newCall("a-1", "x"), newCall("a-2", "y")},
+			callsB: []call{newCall("b-1", "y"), newCall("b-2", "x")},
+			want:   [][2]string{{"a-1", "b-2"}, {"a-2", "b-1"}},
+		},
+		{
+			name:   "unmatched requests are paired in order",
+			callsA: []call{newCall("a-1", "z"), newCall("a-2", "x")},
+			callsB: []call{newCall("b-1", "x"), newCall("b-2", "y")},
+			want:   [][2]string{{"a-2", "b-1"}, {"a-1", "b-2"}},
+		},
+		{
+			name:   "repeated requests are paired once",
+			callsA: []call{newCall("a-1", "x"), newCall("a-2", "x")},
+			callsB: []call{newCall("b-1", "x"), newCall("b-2", "x")},
+			want:   [][2]string{{"a-1", "b-1"}, {"a-2", "b-2"}},
+		},
+		{
+			name:   "extra calls of the first set are left out",
+			callsA: []call{newCall("a-1", "x"), newCall("a-2", "y")},
+			callsB: []call{newCall("b-1", "y")},
+			want:   [][2]string{{"a-2", "b-1"}},
+		},
+		{
+			name:   "extra calls of the second set are left out",
+			callsA: []call{newCall("a-1", "x")},
+			callsB: []call{newCall("b-1", "y"), newCall("b-2", "z")},
+			want:   [][2]string{{"a-1", "b-1"}},
+		},
+		{
+			name:   "empty set",
+			callsA: []call{newCall("a-1", "x")},
+			callsB: nil,
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]string
+			for _, pair := range pairCalls(tt.callsA, tt.callsB) {
+				got = append(got, [2]string{pair[0].tc.Name, pair[1].tc.Name})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pairCalls() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{pointer: "", want: ""},
+		{pointer: "/", want: ""},
+		{pointer: "/id", want: "id"},
+		{pointer: "/user/name", want: "user.name"},
+		{pointer: "/items/0/price", want: "items.price"},
+		{pointer: "/0/1/id", want: "id"},
+		{pointer: "/matrix/2/10", want: "matrix"},
+		{pointer: "/a~1b/c~0d", want: "a/b.c~d"},
+		{pointer: "/a~01", want: "a~1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			if got := fieldPath(tt.pointer); got != tt.want {
+				t.Errorf("fieldPath(%q) = %q, want %q", tt.pointer, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package diff
+
+type Differ interface {
+	// Diff compares the testcases recorded in two test sets, and prints the endpoints added
+	// and removed in the second test set along with the changes in the responses of the
+	// endpoints recorded in both. The format is either text or json.
+	Diff(path, setA, setB, format string) error
+}
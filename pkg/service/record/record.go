@@ -3,6 +3,7 @@ package record
 import (
 	"go.keploy.io/server/pkg"
 	"go.keploy.io/server/pkg/hooks"
+	"go.keploy.io/server/pkg/hooks/connection"
 	"go.keploy.io/server/pkg/models"
 	"go.keploy.io/server/pkg/platform/yaml"
 	"go.keploy.io/server/pkg/proxy"
@@ -22,15 +23,33 @@ func NewRecorder(logger *zap.Logger) Recorder {
 }
 
 // func (r *recorder) CaptureTraffic(tcsPath, mockPath string, appCmd, appContainer, appNetwork string, Delay uint64) {
-func (r *recorder) CaptureTraffic(path string, appCmd, appContainer, appNetwork string, Delay uint64, ports []uint) {
+func (r *recorder) CaptureTraffic(path string, appCmd, appContainer, appNetwork string, Delay uint64, ports []uint, filter models.RecordFilter, redact models.RedactOptions) {
 	models.SetMode(models.MODE_RECORD)
 
+	recordFilter, err := connection.NewFilter(filter)
+	if err != nil {
+		r.logger.Error("invalid filter for the recorded requests", zap.Error(err))
+		return
+	}
+
+	redactor, err := yaml.NewRedactor(redact)
+	if err != nil {
+		r.logger.Error("invalid rules for the redaction of the recorded secrets", zap.Error(err))
+		return
+	}
+
 	dirName, err := yaml.NewSessionIndex(path, r.logger)
 	if err != nil {
 		return
 	}
 
-	ys := yaml.NewYamlStore(path+"/"+dirName+"/tests", path+"/"+dirName, "", "", r.logger)
+	ys := &yaml.Yaml{
+		TcsPath:  path + "/" + dirName + "/tests",
+		MockPath: path + "/" + dirName,
+		Logger:   r.logger,
+		Dedupe:   filter.Dedupe,
+		Redactor: redactor,
+	}
 
 	routineId := pkg.GenerateRandomID()
 	// Initiate the hooks and update the vaccant ProxyPorts map
@@ -39,6 +58,8 @@ func (r *recorder) CaptureTraffic(path string, appCmd, appContainer, appNetwork
 	// Recover from panic and gracfully shutdown
 	defer loadedHooks.Recover(routineId)
 
+	loadedHooks.SetRecordFilter(recordFilter)
+
 	// load the ebpf hooks into the kernel
 	if err := loadedHooks.LoadHooks(appCmd, appContainer, 0); err != nil {
 		return
@@ -61,6 +82,7 @@ func (r *recorder) CaptureTraffic(path string, appCmd, appContainer, appNetwork
 		r.logger.Error("failed to process user application hence stopping keploy", zap.Error(err))
 		loadedHooks.Stop(true)
 		ps.StopProxyServer()
+		pruneSkippedMocks(ys, r.logger)
 		return
 	}
 
@@ -73,4 +95,14 @@ func (r *recorder) CaptureTraffic(path string, appCmd, appContainer, appNetwork
 
 	//stop listening for proxy server
 	ps.StopProxyServer()
+
+	pruneSkippedMocks(ys, r.logger)
+}
+
+// pruneSkippedMocks removes the mocks of the duplicate testcases which weren't recorded, since
+// they don't belong to any of the recorded testcases.
+func pruneSkippedMocks(ys *yaml.Yaml, logger *zap.Logger) {
+	if err := ys.PruneSkippedMocks(); err != nil {
+		logger.Error("failed to remove the mocks of the skipped duplicate testcases", zap.Error(err))
+	}
 }
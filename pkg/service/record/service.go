@@ -1,6 +1,8 @@
 package record
 
+import "go.keploy.io/server/pkg/models"
+
 type Recorder interface {
 	// CaptureTraffic(tcsPath, mockPath string, appCmd, appContainer, networkName string, Delay uint64)
-	CaptureTraffic(path string, appCmd, appContainer, networkName string, Delay uint64, ports []uint)
+	CaptureTraffic(path string, appCmd, appContainer, networkName string, Delay uint64, ports []uint, filter models.RecordFilter, redact models.RedactOptions)
 }
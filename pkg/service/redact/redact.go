@@ -0,0 +1,93 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/olekukonko/tablewriter"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+var Emoji = "\U0001F430" + " Keploy:"
+
+type redactor struct {
+	logger *zap.Logger
+}
+
+func NewRedactor(logger *zap.Logger) Redactor {
+	return &redactor{
+		logger: logger,
+	}
+}
+
+func (r *redactor) Redact(path string, testSets []string, opts models.RedactOptions) error {
+	rules, err := yaml.NewRedactor(opts)
+	if err != nil {
+		r.logger.Error("invalid rules for the redaction of the recorded secrets", zap.Error(err))
+		return err
+	}
+	if rules == nil {
+		r.logger.Error("no redaction rules, pass the headers, fields, patterns or detectors to redact")
+		return errors.New("no redaction rules")
+	}
+
+	if len(testSets) == 0 {
+		sessions, err := yaml.ReadSessionIndices(path, r.logger)
+		if err != nil {
+			r.logger.Error("failed to read the test sets", zap.Error(err))
+			return err
+		}
+		testSets = sessions
+	}
+
+	rows := [][]string{}
+	for _, testSet := range testSets {
+		dir := filepath.Join(path, testSet)
+		if _, err := os.Stat(dir); err != nil {
+			r.logger.Error("failed to find the test set", zap.Error(err), zap.Any("test-set", testSet))
+			return err
+		}
+		ys := yaml.NewYamlStore(filepath.Join(dir, "tests"), dir, "", "", r.logger)
+		tcs, err := ys.ReadTestcase(filepath.Join(dir, "tests"), nil)
+		if err != nil {
+			r.logger.Error("failed to read the testcases of the test set", zap.Error(err), zap.Any("test-set", testSet))
+			return err
+		}
+		for _, tc := range tcs {
+			n := rules.Testcase(tc)
+			if n == 0 {
+				continue
+			}
+			// the testcase keeps its name, so it is rewritten in place
+			if err := ys.WriteTestcase(tc); err != nil {
+				return err
+			}
+			rows = append(rows, []string{testSet, tc.Name, fmt.Sprint(n)})
+		}
+
+		n, err := yaml.RedactMocks(dir, rules, r.logger)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			rows = append(rows, []string{testSet, "mocks", fmt.Sprint(n)})
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("%s no secrets found in the test sets\n", Emoji)
+		return nil
+	}
+	fmt.Printf("%s redacted the secrets of the test sets\n", Emoji)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Test Set", "File", "Redacted Values"})
+	table.SetAutoWrapText(false)
+	table.SetAutoMergeCellsByColumnIndex([]int{0})
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
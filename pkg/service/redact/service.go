@@ -0,0 +1,10 @@
+package redact
+
+import "go.keploy.io/server/pkg/models"
+
+type Redactor interface {
+	// Redact replaces the secrets in the testcases and mocks of the test sets with placeholders,
+	// by the rules of the redact options, and rewrites them in place. All the test sets of the
+	// path are redacted when none are passed.
+	Redact(path string, testSets []string, opts models.RedactOptions) error
+}
@@ -10,6 +10,7 @@ import (
 
 	"go.keploy.io/server/pkg/platform/yaml"
 	"go.keploy.io/server/pkg/service/serve/graph/model"
+	"go.keploy.io/server/pkg/service/test"
 	"go.uber.org/zap"
 )
 
@@ -63,7 +64,7 @@ func (r *mutationResolver) RunTestSet(ctx context.Context, testSet string) (*mod
 
 	go func() {
 		r.Logger.Debug("starting testrun...", zap.Any("testSet", testSet))
-		tester.RunTestSet(testSet, testCasePath, testReportPath, "", "", "", delay, pid, ys, loadedHooks, testReportFS, testRunChan, r.ApiTimeout)
+		tester.RunTestSet(testSet, testCasePath, testReportPath, "", "", "", delay, pid, ys, loadedHooks, testReportFS, testRunChan, r.ApiTimeout, test.Option{})
 	}()
 
 	testRunID := <-testRunChan
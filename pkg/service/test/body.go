@@ -0,0 +1,194 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Body is a response body along with its content type.
+type Body struct {
+	ContentType string
+	Data        string
+}
+
+// BodyCompareOptions are the options of the testcase and its test set for comparing the bodies.
+type BodyCompareOptions struct {
+	// Noise lists the noisy fields of the body, without the "body." prefix.
+	Noise  []string
+	Match  models.MatchOptions
+	Config *models.TestSetConfig
+	Logger *zap.Logger
+}
+
+// BodyComparator compares the response bodies of the content types it is registered for.
+type BodyComparator interface {
+	// Compare returns the expected and actual bodies in a canonical form without the noisy
+	// fields, which is used to render their diffs, and whether they match.
+	Compare(exp, act Body, opts BodyCompareOptions) (string, string, bool, error)
+}
+
+var (
+	comparatorsMu   sync.RWMutex
+	bodyComparators = map[string]BodyComparator{
+		"application/x-www-form-urlencoded": formComparator{},
+		"multipart/form-data":               multipartComparator{},
+		"multipart/mixed":                   multipartComparator{},
+		"application/xml":                   xmlComparator{},
+		"text/xml":                          xmlComparator{},
+		"application/protobuf":              protobufComparator{},
+		"application/x-protobuf":            protobufComparator{},
+		"application/vnd.google.protobuf":   protobufComparator{},
+	}
+)
+
+// RegisterBodyComparator registers the comparator for the bodies of the media type, e.g.
+// "application/xml". It replaces the comparator registered earlier for the media type.
+func RegisterBodyComparator(mediaType string, c BodyComparator) {
+	comparatorsMu.Lock()
+	defer comparatorsMu.Unlock()
+	bodyComparators[strings.ToLower(mediaType)] = c
+}
+
+// bodyComparatorFor returns the comparator registered for the media type of the content
+// type. The structured syntax suffix is used for the media types without a comparator, e.g.
+// "application/atom+xml" is compared as xml.
+func bodyComparatorFor(contentType string) BodyComparator {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	comparatorsMu.RLock()
+	defer comparatorsMu.RUnlock()
+	if c, ok := bodyComparators[mediaType]; ok {
+		return c
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		return bodyComparators["application/"+mediaType[i+1:]]
+	}
+	return nil
+}
+
+// matchAsJson compares the bodies converted into json, so that the noise and the match options
+// of the json bodies apply to them as well.
+func matchAsJson(exp, act interface{}, opts BodyCompareOptions) (string, string, bool, error) {
+	expJson, err := json.Marshal(exp)
+	if err != nil {
+		return "", "", false, err
+	}
+	actJson, err := json.Marshal(act)
+	if err != nil {
+		return "", "", false, err
+	}
+	return Match(string(expJson), string(actJson), opts.Noise, opts.Match, opts.Logger)
+}
+
+// addField adds the value of a field to the object, turning the repeated fields into lists.
+func addField(obj map[string]interface{}, key string, value interface{}) {
+	prev, ok := obj[key]
+	if !ok {
+		obj[key] = value
+		return
+	}
+	if list, ok := prev.([]interface{}); ok {
+		obj[key] = append(list, value)
+		return
+	}
+	obj[key] = []interface{}{prev, value}
+}
+
+// formComparator compares the url encoded forms irrespective of the order of their fields.
+type formComparator struct{}
+
+func (formComparator) Compare(exp, act Body, opts BodyCompareOptions) (string, string, bool, error) {
+	expForm, err := decodeForm(exp.Data)
+	if err != nil {
+		return "", "", false, err
+	}
+	actForm, err := decodeForm(act.Data)
+	if err != nil {
+		return "", "", false, err
+	}
+	return matchAsJson(expForm, actForm, opts)
+}
+
+func decodeForm(data string) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the form body. error: %v", err)
+	}
+	form := map[string]interface{}{}
+	for key, vals := range values {
+		for _, v := range vals {
+			addField(form, key, v)
+		}
+	}
+	return form, nil
+}
+
+// multipartComparator compares the parts of the multipart bodies by their names, since the
+// boundaries are generated afresh for every response. A part is compared as its value, or as
+// its filename, content type and content when it is a file.
+type multipartComparator struct{}
+
+func (multipartComparator) Compare(exp, act Body, opts BodyCompareOptions) (string, string, bool, error) {
+	expParts, err := decodeMultipart(exp)
+	if err != nil {
+		return "", "", false, err
+	}
+	actParts, err := decodeMultipart(act)
+	if err != nil {
+		return "", "", false, err
+	}
+	return matchAsJson(expParts, actParts, opts)
+}
+
+func decodeMultipart(body Body) (map[string]interface{}, error) {
+	_, params, err := mime.ParseMediaType(body.ContentType)
+	if err != nil || params["boundary"] == "" {
+		return nil, fmt.Errorf("the multipart body does not have a boundary in its content type %q", body.ContentType)
+	}
+	reader := multipart.NewReader(strings.NewReader(body.Data), params["boundary"])
+	parts := map[string]interface{}{}
+	for i := 0; ; i++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the multipart body. error: %v", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the part of the multipart body. error: %v", err)
+		}
+		name := part.FormName()
+		if name == "" {
+			name = fmt.Sprintf("part%d", i)
+		}
+		if part.FileName() == "" && part.Header.Get("Content-Type") == "" {
+			addField(parts, name, string(content))
+			continue
+		}
+		// the json content is compared structurally as well
+		var value interface{} = string(content)
+		var decoded interface{}
+		if json.Valid(content) && json.NewDecoder(bytes.NewReader(content)).Decode(&decoded) == nil {
+			value = decoded
+		}
+		addField(parts, name, map[string]interface{}{
+			"filename":     part.FileName(),
+			"content_type": part.Header.Get("Content-Type"),
+			"content":      value,
+		})
+	}
+}
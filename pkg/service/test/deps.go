@@ -0,0 +1,50 @@
+package test
+
+import (
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// verifyDeps fills the dependency results of the testcase with its outgoing calls and the
+// mocks of the testcase which were left unused. In strict mode, the testcase fails if an
+// outgoing call did not match any mock or a mock of the testcase was not used.
+func (t *tester) verifyDeps(result *models.TestResult, calls []models.DepCall, unusedMocks []*models.Mock) {
+	pass := true
+	for _, call := range calls {
+		dep := models.DepResult{
+			Type: string(call.Kind),
+			Meta: []models.DepMetaResult{{
+				Normal: call.Mock != nil,
+				Key:    "request",
+				Actual: call.Actual,
+			}},
+		}
+		if call.Mock != nil {
+			dep.Name = call.Mock.Name
+			dep.Meta[0].Expected = call.Mock.RequestString()
+		} else {
+			pass = false
+			t.logger.Warn("the outgoing call of the testcase did not match any mock", zap.Any("testcase id", result.TestCaseID), zap.Any("kind", call.Kind), zap.Any("request", call.Actual))
+		}
+		result.Result.DepResult = append(result.Result.DepResult, dep)
+	}
+
+	for _, m := range unusedMocks {
+		pass = false
+		t.logger.Warn("the mock of the testcase was not used by any outgoing call", zap.Any("testcase id", result.TestCaseID), zap.Any("kind", m.Kind), zap.Any("request", m.RequestString()))
+		result.Result.DepResult = append(result.Result.DepResult, models.DepResult{
+			Name: m.Name,
+			Type: string(m.Kind),
+			Meta: []models.DepMetaResult{{
+				Normal:   false,
+				Key:      "request",
+				Expected: m.RequestString(),
+			}},
+		})
+	}
+
+	if !pass && result.Status == models.TestStatusPassed {
+		t.logger.Info("result", zap.Any("testcase id", result.TestCaseID), zap.Any("passed", false), zap.Any("reason", "unmatched outgoing calls or unused mocks"))
+		result.Status = models.TestStatusFailed
+	}
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/proxy/integrations/grpcparser"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+)
+
+// simulateGrpc replays the recorded unary gRPC call of the testcase against the user
+// application over cleartext HTTP/2 and returns the decoded response.
+func simulateGrpc(tc models.TestCase, logger *zap.Logger, apiTimeout uint64) (*models.GrpcResp, error) {
+	logger.Info("making a gRPC request", zap.Any("test case id", tc.Name))
+
+	authority := tc.GrpcReq.Headers.PseudoHeaders[":authority"]
+	path := tc.GrpcReq.Headers.PseudoHeaders[":path"]
+	if authority == "" || path == "" {
+		return nil, fmt.Errorf("the gRPC testcase %s does not have the :authority or :path pseudo header", tc.Name)
+	}
+
+	payload, err := grpcparser.CreatePayloadFromLengthPrefixedMessage(tc.GrpcReq.Body)
+	if err != nil {
+		logger.Error("failed to create the gRPC payload from the yaml document", zap.Error(err))
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+authority+path, bytes.NewReader(payload))
+	if err != nil {
+		logger.Error("failed to create a gRPC request from the yaml document", zap.Error(err))
+		return nil, err
+	}
+	for key, value := range tc.GrpcReq.Headers.OrdinaryHeaders {
+		// the content length is computed by the transport from the payload
+		if strings.EqualFold(key, "content-length") {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("KEPLOY_TEST_ID", tc.Name)
+
+	logger.Debug(fmt.Sprintf("Sending gRPC request to user app:%v", req))
+
+	// gRPC servers without TLS only speak HTTP/2 with prior knowledge (h2c)
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(apiTimeout),
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		logger.Error("failed sending gRPC testcase request to app", zap.Error(err))
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	// trailers are only populated once the body is read till EOF
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		logger.Error("failed reading gRPC response body", zap.Error(err))
+		return nil, err
+	}
+
+	resp := models.NewGrpcStream(0).GrpcResp
+	// A failed call without any message may be sent as a single HEADERS frame which is
+	// also the trailer (trailers-only response). It is recorded as the trailer as well.
+	headers := resp.Headers
+	if len(respBody) == 0 && len(httpResp.Trailer) == 0 && httpResp.Header.Get("grpc-status") != "" {
+		headers = resp.Trailers
+	}
+	headers.PseudoHeaders[":status"] = strconv.Itoa(httpResp.StatusCode)
+	for key, values := range httpResp.Header {
+		headers.OrdinaryHeaders[strings.ToLower(key)] = strings.Join(values, ", ")
+	}
+	for key, values := range httpResp.Trailer {
+		resp.Trailers.OrdinaryHeaders[strings.ToLower(key)] = strings.Join(values, ", ")
+	}
+	resp.Body = grpcparser.CreateLengthPrefixedMessageFromPayload(respBody)
+
+	return &resp, nil
+}
+
+// grpcHeaders converts the gRPC headers into a http.Header so that they can be compared
+// using CompareHeaders. The keys are kept as they are on the wire (lowercase).
+func grpcHeaders(headers models.GrpcHeaders) http.Header {
+	h := http.Header{}
+	for key, value := range headers.PseudoHeaders {
+		h[key] = []string{value}
+	}
+	for key, value := range headers.OrdinaryHeaders {
+		h[key] = []string{value}
+	}
+	return h
+}
+
+func (t *tester) testGrpc(tc models.TestCase, actualResponse *models.GrpcResp, cfg *models.TestSetConfig) (bool, *models.Result) {
+	pass := true
+	res := &models.Result{
+		BodyResult: []models.BodyResult{{
+			Normal:   false,
+			Type:     models.BodyTypePlain,
+			Expected: tc.GrpcResp.Body.DecodedData,
+			Actual:   actualResponse.Body.DecodedData,
+		}},
+	}
+
+	var (
+		headerNoise  = map[string]string{}
+		trailerNoise = map[string]string{}
+	)
+	for _, n := range tc.Noise {
+		a := strings.Split(n, ".")
+		switch {
+		case a[0] == "header":
+			headerNoise[a[len(a)-1]] = a[len(a)-1]
+		case a[0] == "trailer":
+			trailerNoise[a[len(a)-1]] = a[len(a)-1]
+		}
+	}
+
+	if Contains(tc.Noise, "body") ||
+		(tc.GrpcResp.Body.DecodedData == actualResponse.Body.DecodedData &&
+			tc.GrpcResp.Body.CompressionFlag == actualResponse.Body.CompressionFlag) {
+		res.BodyResult[0].Normal = true
+	} else {
+		pass = false
+	}
+
+	hRes := &[]models.HeaderResult{}
+	expHeaders, actHeaders := grpcHeaders(tc.GrpcResp.Headers), grpcHeaders(actualResponse.Headers)
+	if !t.matchHeaders(cfg, expHeaders, actHeaders, hRes, headerNoise) {
+		pass = false
+	}
+	if !CompareHeaders(expHeaders, actHeaders, hRes, headerNoise) {
+		pass = false
+	}
+	res.HeadersResult = *hRes
+
+	tRes := &[]models.HeaderResult{}
+	expTrailers, actTrailers := grpcHeaders(tc.GrpcResp.Trailers), grpcHeaders(actualResponse.Trailers)
+	if !t.matchHeaders(cfg, expTrailers, actTrailers, tRes, trailerNoise) {
+		pass = false
+	}
+	if !CompareHeaders(expTrailers, actTrailers, tRes, trailerNoise) {
+		pass = false
+	}
+	res.TrailerResult = *tRes
+
+	logger := pp.New()
+	logger.WithLineInfo = false
+	if !pass {
+		logDiffs := NewDiffsPrinter(tc.Name)
+		logger.SetColorScheme(models.FailingColorScheme)
+		logs := logger.Sprintf("Testrun failed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name)
+
+		for _, j := range res.HeadersResult {
+			if !j.Normal {
+				logDiffs.PushHeaderDiff(fmt.Sprint(j.Expected.Value), fmt.Sprint(j.Actual.Value), headerNoise)
+			}
+		}
+		for _, j := range res.TrailerResult {
+			if !j.Normal {
+				logDiffs.PushHeaderDiff(fmt.Sprint(j.Expected.Value), fmt.Sprint(j.Actual.Value), trailerNoise)
+			}
+		}
+		if !res.BodyResult[0].Normal {
+			logDiffs.PushBodyDiff(tc.GrpcResp.Body.DecodedData, actualResponse.Body.DecodedData, nil)
+		}
+
+		t.mutex.Lock()
+		logger.Printf(logs)
+		logDiffs.Render()
+		t.mutex.Unlock()
+	} else {
+		logger.SetColorScheme(models.PassingColorScheme)
+		logs := logger.Sprintf("Testrun passed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name)
+		t.mutex.Lock()
+		logger.Printf(logs)
+		t.mutex.Unlock()
+	}
+
+	return pass, res
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// DefaultIgnoredHeaders are the headers which differ between the runs of an application
+// without any change in its behaviour, so they are not compared unless the test set config
+// opts out of them.
+var DefaultIgnoredHeaders = []string{
+	"Date",
+	"Server",
+	"Traceparent",
+	"Tracestate",
+	"Baggage",
+	"B3",
+	"X-B3-*",
+	"Uber-Trace-Id",
+	"X-Amzn-Trace-Id",
+	"X-Cloud-Trace-Context",
+	"X-Datadog-*",
+	"Sentry-Trace",
+}
+
+// namePattern matches the header names against a glob, or a regex between slashes.
+type namePattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+func newNamePattern(p string) (namePattern, error) {
+	if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+		re, err := regexp.Compile("(?i)" + p[1:len(p)-1])
+		if err != nil {
+			return namePattern{}, fmt.Errorf("invalid regex for the header name %s. error: %v", p, err)
+		}
+		return namePattern{re: re}, nil
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return namePattern{}, fmt.Errorf("invalid glob for the header name %s. error: %v", p, err)
+	}
+	return namePattern{glob: strings.ToLower(p)}, nil
+}
+
+func (p namePattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	ok, _ := path.Match(p.glob, strings.ToLower(name))
+	return ok
+}
+
+func (p namePattern) exact() bool {
+	return p.re == nil && !strings.ContainsAny(p.glob, `*?[\`)
+}
+
+type headerRule struct {
+	raw     string
+	name    namePattern
+	value   *regexp.Regexp
+	present bool
+}
+
+// headerMatcher decides which headers are compared exactly, ignored or matched by the rules.
+type headerMatcher struct {
+	ignore []namePattern
+	rules  []headerRule
+}
+
+// newHeaderMatcher compiles the header options of the test set, along with the header noise
+// of the testcase, whose names may be globs as well.
+func newHeaderMatcher(opts *models.HeaderOptions, noise map[string]string) (*headerMatcher, error) {
+	if opts == nil {
+		opts = &models.HeaderOptions{}
+	}
+	var names []string
+	if !opts.NoDefaults {
+		names = append(names, DefaultIgnoredHeaders...)
+	}
+	names = append(names, opts.Ignore...)
+	for name := range noise {
+		names = append(names, name)
+	}
+
+	m := &headerMatcher{}
+	for _, name := range names {
+		p, err := newNamePattern(name)
+		if err != nil {
+			return nil, err
+		}
+		m.ignore = append(m.ignore, p)
+	}
+	for _, r := range opts.Rules {
+		name, err := newNamePattern(r.Name)
+		if err != nil {
+			return nil, err
+		}
+		rule := headerRule{raw: r.Name, name: name, present: r.Present}
+		if r.Value != "" {
+			rule.value, err = regexp.Compile(r.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex for the value of the header %s. error: %v", r.Name, err)
+			}
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// apply matches the headers with the rules and stores their results, and adds the ignored
+// headers and the headers matched by the rules to the noise, so that CompareHeaders skips them.
+// It returns false if any of the rules fails.
+func (m *headerMatcher) apply(exp, act http.Header, noise map[string]string, res *[]models.HeaderResult) bool {
+	names := map[string]bool{}
+	for k := range exp {
+		names[k] = true
+	}
+	for k := range act {
+		names[k] = true
+	}
+	// the framing headers differ when the app switches between the chunked responses and the
+	// ones with a content length
+	for k := range names {
+		switch {
+		case strings.EqualFold(k, "Content-Length"):
+			if len(exp.Values(k)) == 0 && chunked(exp) || len(act.Values(k)) == 0 && chunked(act) {
+				noise[k] = k
+			}
+		case strings.EqualFold(k, "Transfer-Encoding"):
+			// the testcases recorded before the Transfer-Encoding was kept have neither of them
+			if len(exp.Values(k)) == 0 && (len(exp.Values("Content-Length")) > 0 || len(act.Values("Content-Length")) == 0) ||
+				len(act.Values(k)) == 0 && len(act.Values("Content-Length")) > 0 {
+				noise[k] = k
+			}
+		}
+	}
+
+	pass := true
+	for _, rule := range m.rules {
+		matched := false
+		for k := range names {
+			if !rule.name.match(k) {
+				continue
+			}
+			matched = true
+			noise[k] = k
+			if !checkKey(res, k) {
+				continue
+			}
+			ok := rule.matchValue(act[k])
+			pass = pass && ok
+			*res = append(*res, models.HeaderResult{
+				Normal:   ok,
+				Expected: models.Header{Key: k, Value: exp[k]},
+				Actual:   models.Header{Key: k, Value: act[k]},
+			})
+		}
+		// the header named by the rule is missing from both the responses
+		if !matched && rule.name.exact() && checkKey(res, rule.raw) {
+			pass = false
+			*res = append(*res, models.HeaderResult{
+				Normal:   false,
+				Expected: models.Header{Key: rule.raw},
+				Actual:   models.Header{Key: rule.raw},
+			})
+		}
+	}
+
+	for k := range names {
+		for _, p := range m.ignore {
+			if p.match(k) {
+				noise[k] = k
+				break
+			}
+		}
+	}
+	return pass
+}
+
+// chunked checks whether the response is sent in chunks instead of with a content length.
+func chunked(h http.Header) bool {
+	return strings.Contains(strings.ToLower(h.Get("Transfer-Encoding")), "chunked")
+}
+
+func (r headerRule) matchValue(values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	if r.value == nil {
+		return true
+	}
+	return r.value.MatchString(strings.Join(values, ", "))
+}
+
+// matchHeaders applies the header options of the test set and the header noise of the testcase
+// to the headers. It returns false if any of the header rules fails.
+func (t *tester) matchHeaders(cfg *models.TestSetConfig, exp, act http.Header, res *[]models.HeaderResult, noise map[string]string) bool {
+	var opts *models.HeaderOptions
+	if cfg != nil {
+		opts = cfg.Headers
+	}
+	m, err := newHeaderMatcher(opts, noise)
+	if err != nil {
+		t.logger.Warn("failed to compile the header options, the headers are compared exactly", zap.Error(err))
+		return true
+	}
+	return m.apply(exp, act, noise, res)
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestHeaderMatcherApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  *models.HeaderOptions
+		noise map[string]string
+		exp   http.Header
+		act   http.Header
+		want  bool
+		// wantNoise are the headers skipped by CompareHeaders
+		wantNoise []string
+		// wantResults are the headers matched by the rules, with whether they passed
+		wantResults map[string]bool
+	}{
+		{
+			name:      "default ignored headers",
+			exp:       http.Header{"Date": {"Mon"}, "X-B3-Traceid": {"a"}, "Content-Type": {"text/plain"}},
+			act:       http.Header{"Date": {"Tue"}, "X-B3-Traceid": {"b"}, "Content-Type": {"text/plain"}},
+			want:      true,
+			wantNoise: []string{"Date", "X-B3-Traceid"},
+		},
+		{
+			name:      "defaults disabled",
+			opts:      &models.HeaderOptions{NoDefaults: true, Ignore: []string{"X-Request-*"}},
+			exp:       http.Header{"Date": {"Mon"}, "X-Request-Id": {"1"}},
+			act:       http.Header{"Date": {"Tue"}, "X-Request-Id": {"2"}},
+			want:      true,
+			wantNoise: []string{"X-Request-Id"},
+		},
+		{
+			name:      "regex names and noise globs",
+			opts:      &models.HeaderOptions{NoDefaults: true, Ignore: []string{"/^x-amz-.*$/"}},
+			noise:     map[string]string{"Etag*": ""},
+			exp:       http.Header{"X-Amz-Id": {"1"}, "Etag": {"a"}, "Vary": {"Origin"}},
+			act:       http.Header{"X-Amz-Id": {"2"}, "Etag": {"b"}, "Vary": {"Origin"}},
+			want:      true,
+			wantNoise: []string{"Etag", "Etag*", "X-Amz-Id"},
+		},
+		{
+			name:        "value rule passes",
+			opts:        &models.HeaderOptions{NoDefaults: true, Rules: []models.HeaderRule{{Name: "X-Request-Id", Value: "^[0-9a-f-]{36}$"}}},
+			exp:         http.Header{"X-Request-Id": {"3f2b1c9e-0000-4000-8000-000000000001"}},
+			act:         http.Header{"X-Request-Id": {"9a8b7c6d-0000-4000-8000-000000000002"}},
+			want:        true,
+			wantNoise:   []string{"X-Request-Id"},
+			wantResults: map[string]bool{"X-Request-Id": true},
+		},
+		{
+			name:        "value rule fails",
+			opts:        &models.HeaderOptions{NoDefaults: true, Rules: []models.HeaderRule{{Name: "Cache-Control", Value: "max-age=\\d+"}}},
+			exp:         http.Header{"Cache-Control": {"max-age=60"}},
+			act:         http.Header{"Cache-Control": {"no-store"}},
+			want:        false,
+			wantNoise:   []string{"Cache-Control"},
+			wantResults: map[string]bool{"Cache-Control": false},
+		},
+		{
+			name:        "present rule on a missing header",
+			opts:        &models.HeaderOptions{NoDefaults: true, Rules: []models.HeaderRule{{Name: "Set-Cookie", Present: true}}},
+			exp:         http.Header{"Set-Cookie": {"a=1"}},
+			act:         http.Header{},
+			want:        false,
+			wantNoise:   []string{"Set-Cookie"},
+			wantResults: map[string]bool{"Set-Cookie": false},
+		},
+		{
+			name:        "exact rule on a header missing from both responses",
+			opts:        &models.HeaderOptions{NoDefaults: true, Rules: []models.HeaderRule{{Name: "X-Version", Present: true}}},
+			exp:         http.Header{},
+			act:         http.Header{},
+			want:        false,
+			wantResults: map[string]bool{"X-Version": false},
+		},
+		{
+			name:      "content length of a chunked response",
+			opts:      &models.HeaderOptions{NoDefaults: true},
+			exp:       http.Header{"Content-Length": {"10"}},
+			act:       http.Header{"Transfer-Encoding": {"chunked"}},
+			want:      true,
+			wantNoise: []string{"Content-Length", "Transfer-Encoding"},
+		},
+		{
+			name:      "chunked response replaced by one with a content length",
+			opts:      &models.HeaderOptions{NoDefaults: true},
+			exp:       http.Header{"Transfer-Encoding": {"chunked"}},
+			act:       http.Header{"Content-Length": {"10"}},
+			want:      true,
+			wantNoise: []string{"Content-Length", "Transfer-Encoding"},
+		},
+		{
+			name:      "content length missing from a response which isn't chunked",
+			opts:      &models.HeaderOptions{NoDefaults: true},
+			exp:       http.Header{"Content-Length": {"10"}},
+			act:       http.Header{},
+			want:      true,
+			wantNoise: []string{},
+		},
+		{
+			name:      "chunked testcase recorded without the transfer encoding",
+			opts:      &models.HeaderOptions{NoDefaults: true},
+			exp:       http.Header{},
+			act:       http.Header{"Transfer-Encoding": {"chunked"}},
+			want:      true,
+			wantNoise: []string{"Transfer-Encoding"},
+		},
+		{
+			name:      "chunked response replaced by one without a content length",
+			opts:      &models.HeaderOptions{NoDefaults: true},
+			exp:       http.Header{"Transfer-Encoding": {"chunked"}},
+			act:       http.Header{},
+			want:      true,
+			wantNoise: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noise := map[string]string{}
+			for k, v := range tt.noise {
+				noise[k] = v
+			}
+			m, err := newHeaderMatcher(tt.opts, noise)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var res []models.HeaderResult
+			if got := m.apply(tt.exp, tt.act, noise, &res); got != tt.want {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+
+			gotNoise := []string{}
+			for k := range noise {
+				gotNoise = append(gotNoise, k)
+			}
+			sort.Strings(gotNoise)
+			if tt.wantNoise == nil {
+				tt.wantNoise = []string{}
+			}
+			if !reflect.DeepEqual(gotNoise, tt.wantNoise) {
+				t.Errorf("noise = %v, want %v", gotNoise, tt.wantNoise)
+			}
+
+			gotResults := map[string]bool{}
+			for _, r := range res {
+				gotResults[r.Expected.Key] = r.Normal
+			}
+			if tt.wantResults == nil {
+				tt.wantResults = map[string]bool{}
+			}
+			if !reflect.DeepEqual(gotResults, tt.wantResults) {
+				t.Errorf("results = %v, want %v", gotResults, tt.wantResults)
+			}
+		})
+	}
+}
+
+func TestNewHeaderMatcherInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *models.HeaderOptions
+	}{
+		{name: "invalid glob", opts: &models.HeaderOptions{Ignore: []string{"X-["}}},
+		{name: "invalid name regex", opts: &models.HeaderOptions{Ignore: []string{"/(/"}}},
+		{name: "invalid value regex", opts: &models.HeaderOptions{Rules: []models.HeaderRule{{Name: "X-Id", Value: "("}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newHeaderMatcher(tt.opts, map[string]string{}); err == nil {
+				t.Errorf("newHeaderMatcher() returned no error")
+			}
+		})
+	}
+}
+
+// TestDefaultIgnoredHeaders checks that the headers of the default ignore list only fail the
+// testcases, as they did before the list, when the defaults are disabled.
+func TestDefaultIgnoredHeaders(t *testing.T) {
+	names := map[string]string{
+		"Date":                  "Date",
+		"Server":                "Server",
+		"Traceparent":           "Traceparent",
+		"Tracestate":            "Tracestate",
+		"Baggage":               "Baggage",
+		"B3":                    "B3",
+		"X-B3-*":                "X-B3-Spanid",
+		"Uber-Trace-Id":         "Uber-Trace-Id",
+		"X-Amzn-Trace-Id":       "X-Amzn-Trace-Id",
+		"X-Cloud-Trace-Context": "X-Cloud-Trace-Context",
+		"X-Datadog-*":           "X-Datadog-Trace-Id",
+		"Sentry-Trace":          "Sentry-Trace",
+	}
+	for _, pattern := range DefaultIgnoredHeaders {
+		name, ok := names[pattern]
+		if !ok {
+			t.Fatalf("no header name for the default ignored header %s", pattern)
+		}
+		t.Run(name, func(t *testing.T) {
+			for _, noDefaults := range []bool{false, true} {
+				noise := map[string]string{}
+				m, err := newHeaderMatcher(&models.HeaderOptions{NoDefaults: noDefaults}, noise)
+				if err != nil {
+					t.Fatal(err)
+				}
+				exp, act := http.Header{name: {"recorded"}}, http.Header{name: {"replayed"}}
+				var res []models.HeaderResult
+				pass := m.apply(exp, act, noise, &res) && CompareHeaders(exp, act, &res, noise)
+				if pass == noDefaults {
+					t.Errorf("comparing the header with noDefaults %v = %v, want %v", noDefaults, pass, !noDefaults)
+				}
+			}
+		})
+	}
+}
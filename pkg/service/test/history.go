@@ -0,0 +1,34 @@
+package test
+
+import "go.keploy.io/server/pkg/models"
+
+// historySize is the number of recent test runs kept in the history of a testcase.
+const historySize = 10
+
+// isFlaky checks whether the testcase has both passed and failed in its recent test runs.
+func isFlaky(statuses []models.TestStatus) bool {
+	passed, failed := false, false
+	for _, status := range statuses {
+		switch status {
+		case models.TestStatusPassed:
+			passed = true
+		case models.TestStatusFailed:
+			failed = true
+		}
+	}
+	return passed && failed
+}
+
+// addToHistory appends the status of the testcase in the current test run to its history.
+func addToHistory(history *models.TestHistory, testSet, testCaseID string, status models.TestStatus) {
+	tcs, ok := history.TestSets[testSet]
+	if !ok {
+		tcs = map[string][]models.TestStatus{}
+		history.TestSets[testSet] = tcs
+	}
+	statuses := append(tcs[testCaseID], status)
+	if len(statuses) > historySize {
+		statuses = statuses[len(statuses)-historySize:]
+	}
+	tcs[testCaseID] = statuses
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// hookTimeout is the timeout of the hook commands which don't set their own.
+	hookTimeout = 60 * time.Second
+	// hookOutputSize is the maximum size of the output of a hook kept in the test report.
+	hookOutputSize = 4096
+)
+
+// runHooks runs the hook commands of the stage in order, and stops at the first one which fails.
+// It returns the results of the commands run, and whether all of them passed.
+func (t *tester) runHooks(stage string, cmds []models.HookCommand, testSet, testCase string) ([]models.HookResult, bool) {
+	var results []models.HookResult
+	for _, hook := range cmds {
+		result := t.runHook(stage, hook, testSet, testCase)
+		results = append(results, result)
+		if result.Status != models.TestStatusPassed {
+			return results, false
+		}
+	}
+	return results, true
+}
+
+func (t *tester) runHook(stage string, hook models.HookCommand, testSet, testCase string) models.HookResult {
+	timeout := hookTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Env = append(os.Environ(), "KEPLOY_TEST_SET="+testSet, "KEPLOY_TEST_CASE="+testCase)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// the processes started by the command are killed along with it on timeout
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	t.logger.Debug("running the hook", zap.Any("stage", stage), zap.Any("command", hook.Command), zap.Any("test-set", testSet), zap.Any("testcase id", testCase))
+	started := time.Now()
+	err := cmd.Run()
+	result := models.HookResult{
+		Stage:    stage,
+		Command:  hook.Command,
+		Status:   models.TestStatusPassed,
+		Output:   lastBytes(output.Bytes(), hookOutputSize),
+		Duration: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("the hook timed out after %v", timeout)
+		}
+		result.Status = models.TestStatusFailed
+		result.Error = err.Error()
+		t.logger.Error("the hook failed", zap.Any("stage", stage), zap.Any("command", hook.Command), zap.Error(err), zap.Any("output", result.Output))
+	}
+	return result
+}
+
+// lastBytes returns the last n bytes of the output, which usually hold the cause of a failure.
+func lastBytes(output []byte, n int) string {
+	if len(output) > n {
+		output = output[len(output)-n:]
+	}
+	return string(output)
+}
+
+// hookOptions returns the hooks of the test set config.
+func hookOptions(cfg *models.TestSetConfig) models.HookOptions {
+	if cfg == nil || cfg.Hooks == nil {
+		return models.HookOptions{}
+	}
+	return *cfg.Hooks
+}
+
+// testCaseWithHooks runs the testcase between the beforeEach and afterEach hooks of the test
+// set. The testcase fails without being simulated when a beforeEach hook fails, and fails as
+// well when an afterEach hook fails.
+func (t *tester) testCaseWithHooks(run *testSetRun, tc *models.TestCase, lastAttempt bool) *models.TestResult {
+	hookCfg := hookOptions(run.cfg)
+	if len(hookCfg.BeforeEach) == 0 && len(hookCfg.AfterEach) == 0 {
+		return t.testCase(run, tc, lastAttempt)
+	}
+
+	hookResults, ok := t.runHooks("beforeEach", hookCfg.BeforeEach, run.testSet, tc.Name)
+	var result *models.TestResult
+	if ok {
+		result = t.testCase(run, tc, lastAttempt)
+	} else {
+		now := time.Now().UTC().Unix()
+		result = &models.TestResult{
+			Kind:         tc.Kind,
+			Name:         run.testReportName,
+			Status:       models.TestStatusFailed,
+			Started:      now,
+			Completed:    now,
+			TestCasePath: run.path,
+			TestCaseID:   tc.Name,
+		}
+	}
+
+	afterResults, ok := t.runHooks("afterEach", hookCfg.AfterEach, run.testSet, tc.Name)
+	hookResults = append(hookResults, afterResults...)
+	if result == nil {
+		return nil
+	}
+	result.Hooks = hookResults
+	if !ok {
+		result.Status = models.TestStatusFailed
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"math"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// recordedLatency returns the recorded latency of the http response in milliseconds. The
+// testcases recorded before the latency was stored use the timestamps of their request and
+// response.
+func recordedLatency(tc models.TestCase) int64 {
+	if tc.HttpResp.Latency > 0 {
+		return tc.HttpResp.Latency
+	}
+	if tc.HttpReq.Timestamp.IsZero() || !tc.HttpResp.Timestamp.After(tc.HttpReq.Timestamp) {
+		return 0
+	}
+	return tc.HttpResp.Timestamp.Sub(tc.HttpReq.Timestamp).Milliseconds()
+}
+
+// compareLatency compares the latency of the actual response with the limits of the latency
+// options. The stricter of the ratio to the recorded latency and the absolute budget is used.
+// It returns nil when the latency is not compared.
+func compareLatency(tc models.TestCase, actual *models.HttpResp, opts *models.LatencyOptions) *models.LatencyResult {
+	if opts == nil {
+		return nil
+	}
+	expected := recordedLatency(tc)
+	limit := opts.BudgetMs
+	if opts.MaxRatio > 0 && expected > 0 {
+		ratioLimit := int64(math.Ceil(float64(expected) * opts.MaxRatio))
+		if ratioLimit < opts.MinMs {
+			ratioLimit = opts.MinMs
+		}
+		if limit <= 0 || ratioLimit < limit {
+			limit = ratioLimit
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+	return &models.LatencyResult{
+		Normal:   actual.Latency <= limit,
+		Expected: expected,
+		Actual:   actual.Latency,
+		Limit:    limit,
+	}
+}
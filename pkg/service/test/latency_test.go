@@ -0,0 +1,87 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestCompareLatency(t *testing.T) {
+	recorded := func(latency int64) models.TestCase {
+		return models.TestCase{HttpResp: models.HttpResp{Latency: latency}}
+	}
+	started := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		tc     models.TestCase
+		actual int64
+		opts   *models.LatencyOptions
+		want   *models.LatencyResult
+	}{
+		{name: "no options", tc: recorded(100), actual: 500, opts: nil, want: nil},
+		{name: "no limits", tc: recorded(100), actual: 500, opts: &models.LatencyOptions{WarnOnly: true}, want: nil},
+		{
+			name:   "within the ratio",
+			tc:     recorded(100),
+			actual: 150,
+			opts:   &models.LatencyOptions{MaxRatio: 2},
+			want:   &models.LatencyResult{Normal: true, Expected: 100, Actual: 150, Limit: 200},
+		},
+		{
+			name:   "over the ratio",
+			tc:     recorded(100),
+			actual: 250,
+			opts:   &models.LatencyOptions{MaxRatio: 2},
+			want:   &models.LatencyResult{Normal: false, Expected: 100, Actual: 250, Limit: 200},
+		},
+		{
+			name:   "ratio limit raised to the minimum",
+			tc:     recorded(5),
+			actual: 40,
+			opts:   &models.LatencyOptions{MaxRatio: 2, MinMs: 50},
+			want:   &models.LatencyResult{Normal: true, Expected: 5, Actual: 40, Limit: 50},
+		},
+		{
+			name:   "budget stricter than the ratio",
+			tc:     recorded(100),
+			actual: 180,
+			opts:   &models.LatencyOptions{MaxRatio: 2, BudgetMs: 150},
+			want:   &models.LatencyResult{Normal: false, Expected: 100, Actual: 180, Limit: 150},
+		},
+		{
+			name:   "ratio stricter than the budget",
+			tc:     recorded(100),
+			actual: 180,
+			opts:   &models.LatencyOptions{MaxRatio: 1.5, BudgetMs: 1000},
+			want:   &models.LatencyResult{Normal: false, Expected: 100, Actual: 180, Limit: 150},
+		},
+		{
+			name:   "budget without a recorded latency",
+			tc:     recorded(0),
+			actual: 80,
+			opts:   &models.LatencyOptions{MaxRatio: 2, BudgetMs: 100},
+			want:   &models.LatencyResult{Normal: true, Expected: 0, Actual: 80, Limit: 100},
+		},
+		{
+			name: "latency from the timestamps",
+			tc: models.TestCase{
+				HttpReq:  models.HttpReq{Timestamp: started},
+				HttpResp: models.HttpResp{Timestamp: started.Add(40 * time.Millisecond)},
+			},
+			actual: 90,
+			opts:   &models.LatencyOptions{MaxRatio: 2},
+			want:   &models.LatencyResult{Normal: false, Expected: 40, Actual: 90, Limit: 80},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareLatency(tt.tc, &models.HttpResp{Latency: tt.actual}, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareLatency() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
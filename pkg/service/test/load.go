@@ -0,0 +1,229 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+	"go.uber.org/zap"
+)
+
+// MaxLoadRPS is the highest rate of the load run. The requests are sent on the ticks of a
+// timer, which can't tick faster.
+const MaxLoadRPS = 100000
+
+// LoadOptions configures replaying the recorded testcases as load, after the testcases of a
+// test set are run.
+type LoadOptions struct {
+	Enabled bool
+	// RPS is the number of requests sent per second.
+	RPS uint
+	// Duration is the number of seconds for which the requests are sent.
+	Duration uint64
+	// Concurrency is the maximum number of requests in flight. The requests over it are dropped
+	// to keep the rate, instead of being queued.
+	Concurrency uint
+}
+
+// loadSample is the outcome of a request sent during the load run.
+type loadSample struct {
+	endpoint   string
+	latency    time.Duration
+	statusCode int
+	failed     bool
+	mismatch   bool
+}
+
+// runLoad replays the http testcases of the test set in their order at the target rate, till
+// the duration ends. The mocks are reused by the requests, since the same testcase is replayed
+// many times.
+func (t *tester) runLoad(run *testSetRun, tcs []*models.TestCase, mocks []*models.Mock) *models.LoadReport {
+	opts := run.opts.Load
+	report := &models.LoadReport{
+		Version:  models.V1Beta1,
+		Name:     run.testReportName,
+		TestSet:  run.testSet,
+		RPS:      opts.RPS,
+		Duration: opts.Duration,
+	}
+
+	var httpTcs []*models.TestCase
+	for _, tc := range tcs {
+		if tc.Kind == models.HTTP {
+			httpTcs = append(httpTcs, tc)
+		}
+	}
+	if opts.RPS == 0 || opts.RPS > MaxLoadRPS {
+		t.logger.Warn(fmt.Sprintf("skipping the load run since the rate is not between 1 and %d requests per second", MaxLoadRPS), zap.Any("test-set", run.testSet), zap.Any("rps", opts.RPS))
+		return report
+	}
+	if len(httpTcs) == 0 {
+		t.logger.Warn("skipping the load run since the test set has no http testcases", zap.Any("test-set", run.testSet))
+		return report
+	}
+
+	// the urls are replaced once, rather than for every request
+	targets := make([]string, len(httpTcs))
+	for i, tc := range httpTcs {
+		targets[i] = tc.HttpReq.URL
+		if run.isDocker {
+			targets[i] = t.replaceHost(tc.HttpReq.URL, run.userIp)
+		}
+	}
+
+	run.hooks.ReuseMocks(true)
+	defer run.hooks.ReuseMocks(false)
+	run.hooks.SetTcsMocks(mocks)
+
+	// the requests only read the values extracted by the testcases
+	vars := map[string]string{}
+	for key, value := range run.vars {
+		vars[key] = value
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = opts.RPS
+	}
+	report.Concurrency = concurrency
+	var (
+		samples []loadSample
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		ticker  = time.NewTicker(time.Second / time.Duration(opts.RPS))
+		end     = time.After(time.Duration(opts.Duration) * time.Second)
+		// the requests don't log, since there are too many of them
+		quiet = zap.NewNop()
+	)
+	defer ticker.Stop()
+
+	t.logger.Info("replaying the testcases as load", zap.Any("test-set", run.testSet), zap.Any("rps", opts.RPS), zap.Any("duration", opts.Duration))
+	for i := 0; ; i++ {
+		select {
+		case <-end:
+			wg.Wait()
+			report.Total, report.Endpoints = loadStats(samples)
+			return report
+		case <-ticker.C:
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			report.Dropped++
+			continue
+		}
+		report.Sent++
+		wg.Add(1)
+		go func(tc *models.TestCase, target string) {
+			defer run.hooks.Recover(pkg.GenerateRandomID())
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			simTc := *tc
+			simTc.HttpReq.URL = target
+			started := time.Now()
+			resp, err := pkg.SimulateHttp(simTc, vars, quiet, run.apiTimeout)
+			sample := loadSample{
+				endpoint: endpoint(tc),
+				latency:  time.Since(started),
+				failed:   err != nil,
+			}
+			if err == nil {
+				sample.statusCode = resp.StatusCode
+				sample.mismatch = resp.StatusCode != tc.HttpResp.StatusCode
+			}
+			mu.Lock()
+			samples = append(samples, sample)
+			mu.Unlock()
+		}(httpTcs[i%len(httpTcs)], targets[i%len(httpTcs)])
+	}
+}
+
+// endpoint names the endpoint of the http testcase by its method and path template, e.g.
+// "GET /users/{id}", so that the requests for different ids are grouped together.
+func endpoint(tc *models.TestCase) string {
+	path := tc.HttpReq.URL
+	if u, err := url.Parse(tc.HttpReq.URL); err == nil {
+		path = u.Path
+	}
+	return string(tc.HttpReq.Method) + " " + pkg.PathTemplate(path)
+}
+
+// loadStats summarises the samples of the load run in total, and for every endpoint in the
+// order of their names.
+func loadStats(samples []loadSample) (models.LoadStats, []models.LoadResult) {
+	byEndpoint := map[string][]loadSample{}
+	for _, s := range samples {
+		byEndpoint[s.endpoint] = append(byEndpoint[s.endpoint], s)
+	}
+	endpoints := make([]string, 0, len(byEndpoint))
+	for name := range byEndpoint {
+		endpoints = append(endpoints, name)
+	}
+	sort.Strings(endpoints)
+
+	results := make([]models.LoadResult, 0, len(endpoints))
+	for _, name := range endpoints {
+		results = append(results, models.LoadResult{
+			Endpoint:  name,
+			LoadStats: sampleStats(byEndpoint[name]),
+		})
+	}
+	return sampleStats(samples), results
+}
+
+func sampleStats(samples []loadSample) models.LoadStats {
+	stats := models.LoadStats{
+		Requests:    len(samples),
+		StatusCodes: map[int]int{},
+	}
+	if len(samples) == 0 {
+		return stats
+	}
+	latencies := make([]float64, 0, len(samples))
+	var total float64
+	for _, s := range samples {
+		if s.failed {
+			stats.Errors++
+			continue
+		}
+		if s.statusCode >= 500 {
+			stats.Errors++
+		}
+		if s.mismatch {
+			stats.Mismatches++
+		}
+		stats.StatusCodes[s.statusCode]++
+		ms := float64(s.latency) / float64(time.Millisecond)
+		latencies = append(latencies, ms)
+		total += ms
+	}
+	stats.ErrorRate = float64(stats.Errors) / float64(len(samples))
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Float64s(latencies)
+	stats.Latency = models.LatencyStats{
+		P50:  percentile(latencies, 50),
+		P95:  percentile(latencies, 95),
+		P99:  percentile(latencies, 99),
+		Max:  latencies[len(latencies)-1],
+		Mean: total / float64(len(latencies)),
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -3,8 +3,13 @@ package test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 
+	"go.keploy.io/server/pkg/models"
 	"go.uber.org/zap"
 )
 
@@ -27,7 +32,7 @@ func arrayToMap(arr []string) map[string]bool {
 	return res
 }
 
-func Match(exp, act string, noise []string, log *zap.Logger) (string, string, bool, error) {
+func Match(exp, act string, noise []string, opts models.MatchOptions, log *zap.Logger) (string, string, bool, error) {
 
 	noiseMap := arrayToMap(noise)
 	expected, err := unmarshallJson(exp, log)
@@ -38,7 +43,7 @@ func Match(exp, act string, noise []string, log *zap.Logger) (string, string, bo
 	if err != nil {
 		return exp, act, false, err
 	}
-	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+	if reflect.TypeOf(expected) != reflect.TypeOf(actual) && !(opts.CoerceTypes && isScalar(expected) && isScalar(actual)) {
 		return exp, act, false, nil
 	}
 	//tmp := mapClone(noiseMap)
@@ -46,7 +51,7 @@ func Match(exp, act string, noise []string, log *zap.Logger) (string, string, bo
 	//
 	//tmp = mapClone(noiseMap)
 	//actual = removeNoise(actual, tmp)
-	match, err := jsonMatch("", expected, actual, noiseMap)
+	match, err := jsonMatch("", expected, actual, noiseMap, opts)
 	if err != nil {
 		return exp, act, false, err
 	}
@@ -61,10 +66,69 @@ func Match(exp, act string, noise []string, log *zap.Logger) (string, string, bo
 	return string(cleanExp), string(cleanAct), match, nil
 }
 
+// matchOptionsFor returns the options for the field at key. The options of the longest path
+// containing the field replace the options of the response body.
+func matchOptionsFor(opts models.MatchOptions, key string) models.MatchOptions {
+	res, longest := opts, -1
+	for path, pathOpts := range opts.Paths {
+		path = strings.TrimPrefix(path, "body.")
+		if (path == key || strings.HasPrefix(key, path+".")) && len(path) > longest {
+			res, longest = pathOpts, len(path)
+		}
+	}
+	return res
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case float64, string, bool:
+		return true
+	}
+	return false
+}
+
+// scalarMatch compares two JSON scalars. The numbers are equal within the numeric tolerance,
+// and the scalars of different types are compared by their text when types are coerced.
+func scalarMatch(expected, actual interface{}, opts models.MatchOptions) bool {
+	expNum, expIsNum := expected.(float64)
+	actNum, actIsNum := actual.(float64)
+	if expIsNum && actIsNum {
+		return math.Abs(expNum-actNum) <= opts.NumericTolerance
+	}
+	if reflect.TypeOf(expected) == reflect.TypeOf(actual) {
+		return expected == actual
+	}
+	if !opts.CoerceTypes {
+		return false
+	}
+	expText, actText := scalarText(expected), scalarText(actual)
+	if expText == actText {
+		return true
+	}
+	expNum, expErr := strconv.ParseFloat(expText, 64)
+	actNum, actErr := strconv.ParseFloat(actText, 64)
+	return expErr == nil && actErr == nil && math.Abs(expNum-actNum) <= opts.NumericTolerance
+}
+
+func scalarText(v interface{}) string {
+	if n, ok := v.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
 // jsonMatch returns true if expected and actual JSON objects matches(are equal).
-func jsonMatch(key string, expected, actual interface{}, noiseMap map[string]bool) (bool, error) {
+func jsonMatch(key string, expected, actual interface{}, noiseMap map[string]bool, opts models.MatchOptions) (bool, error) {
 
+	// the noisy fields can even change their types
+	if key != "" && noiseMap[key] {
+		return true, nil
+	}
+	keyOpts := matchOptionsFor(opts, key)
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+		if keyOpts.CoerceTypes && isScalar(expected) && isScalar(actual) {
+			return scalarMatch(expected, actual, keyOpts), nil
+		}
 		return false, errors.New("type not matched ")
 	}
 	if expected == nil && actual == nil {
@@ -77,7 +141,7 @@ func jsonMatch(key string, expected, actual interface{}, noiseMap map[string]boo
 	}
 	switch x.Kind() {
 	case reflect.Float64, reflect.String, reflect.Bool:
-		if expected != actual && !noiseMap[key] {
+		if !scalarMatch(expected, actual, keyOpts) {
 			return false, nil
 		}
 
@@ -85,57 +149,146 @@ func jsonMatch(key string, expected, actual interface{}, noiseMap map[string]boo
 		expMap := expected.(map[string]interface{})
 		actMap := actual.(map[string]interface{})
 		for k, v := range expMap {
+			// remove the noisy key from both expected and actual JSON.
+			if noiseMap[prefix+k] {
+				delete(expMap, k)
+				delete(actMap, k)
+				continue
+			}
 			val, ok := actMap[k]
 			if !ok {
 				return false, nil
 			}
-			if x, er := jsonMatch(prefix+k, v, val, noiseMap); !x || er != nil {
+			if x, er := jsonMatch(prefix+k, v, val, noiseMap, opts); !x || er != nil {
 				return false, nil
 			}
-			// remove the noisy key from both expected and actual JSON.
-			if noiseMap[prefix+k] {
-				delete(expMap, prefix+k)
-				delete(actMap, k)
-				continue
-			}
 		}
 		// checks if there is a key which is not present in expMap but present in actMap.
 		for k := range actMap {
 			_, ok := expMap[k]
-			if !ok {
+			if !ok && !matchOptionsFor(opts, prefix+k).IgnoreExtraFields {
 				return false, nil
 			}
 		}
 
 	case reflect.Slice:
-		if noiseMap[key] {
+		expSlice := expected.([]interface{})
+		actSlice := actual.([]interface{})
+		if len(expSlice) != len(actSlice) {
+			return false, nil
+		}
+		if keyOpts.StrictArrayOrder {
+			for i := range expSlice {
+				if x, err := jsonMatch(key, expSlice[i], actSlice[i], noiseMap, opts); err != nil || !x {
+					return false, nil
+				}
+			}
 			return true, nil
 		}
-		expSlice := reflect.ValueOf(expected)
-		actSlice := reflect.ValueOf(actual)
-		if expSlice.Len() != actSlice.Len() {
-			return false, nil
+		if keyOpts.ArrayKey != "" {
+			if matched, ok := keyedArrayMatch(key, expSlice, actSlice, noiseMap, opts, keyOpts.ArrayKey); ok {
+				return matched, nil
+			}
 		}
-		isMatched := true
-		for i := 0; i < expSlice.Len(); i++ {
-
-			isMatchedElement := false
-			for j := 0; j < actSlice.Len(); j++ {
-				if x, err := jsonMatch(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap); err == nil && x {
-					isMatchedElement = true
-					break
+		// every element of the actual array can only match one element of the expected array
+		candidates := make([][]int, len(expSlice))
+		for i := range expSlice {
+			for j := range actSlice {
+				if x, err := jsonMatch(key, expSlice[i], actSlice[j], noiseMap, opts); err == nil && x {
+					candidates[i] = append(candidates[i], j)
 				}
 			}
-			isMatched = isMatchedElement && isMatched
-			// if x, err := jsonMatch(expSlice.Index(i).Interface(), actSlice.Index(i).Interface()); err != nil || !x {
-			// 	return false, nil
-			// }
-
+			if len(candidates[i]) == 0 {
+				return false, nil
+			}
 		}
-		return isMatched, nil
+		return pairElements(candidates, len(actSlice)) != nil, nil
 	default:
 		return false, errors.New("type not registered for json")
 	}
 	return true, nil
 
 }
+
+// pairElements pairs every element of the expected array with a distinct element of the actual
+// array, among the candidates of the expected element, i.e. the actual elements it matches.
+// A greedy pairing fails when the comparison is tolerant, since an element can match several
+// elements, so the pairs are found with augmenting paths. It returns the index of the actual
+// element paired with every expected element, or nil if they can't all be paired.
+func pairElements(candidates [][]int, actLen int) []int {
+	pairOfAct := make([]int, actLen)
+	for j := range pairOfAct {
+		pairOfAct[j] = -1
+	}
+	// augment pairs the expected element i, by taking over the actual element paired with
+	// another expected element when that element can be paired with another actual element.
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for _, j := range candidates[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if pairOfAct[j] == -1 || augment(pairOfAct[j], visited) {
+				pairOfAct[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range candidates {
+		if !augment(i, make([]bool, actLen)) {
+			return nil
+		}
+	}
+
+	pairs := make([]int, len(candidates))
+	for j, i := range pairOfAct {
+		if i != -1 {
+			pairs[i] = j
+		}
+	}
+	return pairs
+}
+
+// keyedArrayMatch pairs the objects of the arrays by the value of arrayKey and compares the
+// pairs. It returns false as the second value if the objects can't be paired by the key.
+func keyedArrayMatch(key string, expSlice, actSlice []interface{}, noiseMap map[string]bool, opts models.MatchOptions, arrayKey string) (bool, bool) {
+	keyOf := func(elem interface{}) (string, bool) {
+		obj, ok := elem.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		id, ok := obj[arrayKey]
+		if !ok || !isScalar(id) {
+			return "", false
+		}
+		return fmt.Sprint(id), true
+	}
+
+	actByKey := map[string]interface{}{}
+	for _, elem := range actSlice {
+		id, ok := keyOf(elem)
+		if !ok {
+			return false, false
+		}
+		if _, dup := actByKey[id]; dup {
+			return false, false
+		}
+		actByKey[id] = elem
+	}
+	for _, elem := range expSlice {
+		id, ok := keyOf(elem)
+		if !ok {
+			return false, false
+		}
+		act, ok := actByKey[id]
+		if !ok {
+			return false, true
+		}
+		if x, err := jsonMatch(key, elem, act, noiseMap, opts); err != nil || !x {
+			return false, true
+		}
+	}
+	return true, true
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestJsonMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		noise    []string
+		opts     models.MatchOptions
+		want     bool
+	}{
+		{name: "equal objects", expected: `{"a":1,"b":"x"}`, actual: `{"b":"x","a":1}`, want: true},
+		{name: "different values", expected: `{"a":1}`, actual: `{"a":2}`, want: false},
+		{name: "missing field", expected: `{"a":1,"b":2}`, actual: `{"a":1}`, want: false},
+		{name: "extra field", expected: `{"a":1}`, actual: `{"a":1,"b":2}`, want: false},
+		{name: "ignored extra field", expected: `{"a":1}`, actual: `{"a":1,"b":2}`, opts: models.MatchOptions{IgnoreExtraFields: true}, want: true},
+		{name: "noisy field", expected: `{"a":1,"ts":10}`, actual: `{"a":1,"ts":"now"}`, noise: []string{"ts"}, want: true},
+		{name: "numeric tolerance", expected: `{"a":1.0}`, actual: `{"a":1.05}`, opts: models.MatchOptions{NumericTolerance: 0.1}, want: true},
+		{name: "coerced types", expected: `{"a":1}`, actual: `{"a":"1"}`, opts: models.MatchOptions{CoerceTypes: true}, want: true},
+		{name: "types not coerced", expected: `{"a":1}`, actual: `{"a":"1"}`, want: false},
+		{name: "unordered arrays", expected: `[1,2,3]`, actual: `[3,1,2]`, want: true},
+		{name: "strict array order", expected: `[1,2,3]`, actual: `[3,1,2]`, opts: models.MatchOptions{StrictArrayOrder: true}, want: false},
+		{name: "arrays of different lengths", expected: `[1,2]`, actual: `[1,2,2]`, want: false},
+		{name: "repeated elements", expected: `[1,1,2]`, actual: `[1,2,2]`, want: false},
+		{
+			// a greedy pairing takes 1.05 for 1.0, leaving nothing for 1.1
+			name:     "tolerant elements need another pairing",
+			expected: `[1.0,1.1]`,
+			actual:   `[1.05,0.95]`,
+			opts:     models.MatchOptions{NumericTolerance: 0.1},
+			want:     true,
+		},
+		{name: "keyed arrays", expected: `[{"id":1,"v":"a"},{"id":2,"v":"b"}]`, actual: `[{"id":2,"v":"b"},{"id":1,"v":"a"}]`, opts: models.MatchOptions{ArrayKey: "id"}, want: true},
+		{name: "keyed arrays with different values", expected: `[{"id":1,"v":"a"}]`, actual: `[{"id":1,"v":"b"}]`, opts: models.MatchOptions{ArrayKey: "id"}, want: false},
+		{
+			name:     "options of a path",
+			expected: `{"price":10,"count":1}`,
+			actual:   `{"price":10.5,"count":1}`,
+			opts:     models.MatchOptions{Paths: map[string]models.MatchOptions{"body.price": {NumericTolerance: 1}}},
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected, actual interface{}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.actual), &actual); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := jsonMatch("", expected, actual, arrayToMap(tt.noise), tt.opts)
+			if got != tt.want {
+				t.Errorf("jsonMatch(%s, %s) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairElements(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates [][]int
+		actLen     int
+		want       []int
+	}{
+		{name: "distinct candidates", candidates: [][]int{{1}, {0}}, actLen: 2, want: []int{1, 0}},
+		{name: "augmenting path", candidates: [][]int{{0, 1}, {0}}, actLen: 2, want: []int{1, 0}},
+		{name: "longer augmenting path", candidates: [][]int{{0, 1}, {1, 2}, {0}}, actLen: 3, want: []int{1, 2, 0}},
+		{name: "shared single candidate", candidates: [][]int{{0}, {0}}, actLen: 2, want: nil},
+		{name: "no candidates", candidates: [][]int{{}}, actLen: 1, want: nil},
+		{name: "empty arrays", candidates: [][]int{}, actLen: 0, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pairElements(tt.candidates, tt.actLen); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pairElements(%v) = %v, want %v", tt.candidates, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// isolateTcsMocks splits the mocks of a test set into the mocks of every testcase, using the
+// window between the request and the response of the testcase during record. The mocks
+// outside of every window (e.g. the calls made by the app at startup) and the mocks recorded
+// without timestamps are returned as the shared mocks. The returned flags report the testcases
+// whose mocks are isolated, which excludes the gRPC testcases and the testcases recorded
+// without timestamps.
+func isolateTcsMocks(tcs []*models.TestCase, mocks []*models.Mock) ([][]*models.Mock, []*models.Mock, []bool) {
+	type window struct {
+		start, end time.Time
+	}
+	var (
+		windows  = make([]window, len(tcs))
+		isolated = make([]bool, len(tcs))
+	)
+	for i, tc := range tcs {
+		if tc.Kind != models.HTTP || tc.HttpReq.Timestamp.IsZero() || tc.HttpResp.Timestamp.IsZero() {
+			continue
+		}
+		windows[i] = window{start: tc.HttpReq.Timestamp, end: tc.HttpResp.Timestamp}
+		isolated[i] = true
+	}
+
+	var (
+		tcsMocks    = make([][]*models.Mock, len(tcs))
+		sharedMocks []*models.Mock
+	)
+	for _, m := range mocks {
+		// when the testcases were recorded concurrently, the mock belongs to the testcase
+		// that started the latest before the outgoing call.
+		owner := -1
+		for i, w := range windows {
+			if !isolated[i] || m.Spec.Timestamp.IsZero() || m.Spec.Timestamp.Before(w.start) || m.Spec.Timestamp.After(w.end) {
+				continue
+			}
+			if owner == -1 || w.start.After(windows[owner].start) {
+				owner = i
+			}
+		}
+		if owner == -1 {
+			sharedMocks = append(sharedMocks, m)
+			continue
+		}
+		tcsMocks[owner] = append(tcsMocks[owner], m)
+	}
+	return tcsMocks, sharedMocks, isolated
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+func TestIsolateTcsMocks(t *testing.T) {
+	base := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+	httpTc := func(name string, start, end int) *models.TestCase {
+		return &models.TestCase{
+			Name:     name,
+			Kind:     models.HTTP,
+			HttpReq:  models.HttpReq{Timestamp: at(start)},
+			HttpResp: models.HttpResp{Timestamp: at(end)},
+		}
+	}
+	mock := func(name string, sec int) *models.Mock {
+		m := &models.Mock{Name: name}
+		if sec >= 0 {
+			m.Spec.Timestamp = at(sec)
+		}
+		return m
+	}
+	names := func(mocks []*models.Mock) []string {
+		var res []string
+		for _, m := range mocks {
+			res = append(res, m.Name)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		tcs      []*models.TestCase
+		mocks    []*models.Mock
+		tcsMocks [][]string
+		shared   []string
+		isolated []bool
+	}{
+		{
+			name:     "mocks inside the windows belong to their testcases",
+			tcs:      []*models.TestCase{httpTc("test-1", 10, 20), httpTc("test-2", 30, 40)},
+			mocks:    []*models.Mock{mock("mock-0", 5), mock("mock-1", 15), mock("mock-2", 35), mock("mock-3", 50)},
+			tcsMocks: [][]string{{"mock-1"}, {"mock-2"}},
+			shared:   []string{"mock-0", "mock-3"},
+			isolated: []bool{true, true},
+		},
+		{
+			name:     "overlapping windows give the mock to the latest started testcase",
+			tcs:      []*models.TestCase{httpTc("test-1", 10, 40), httpTc("test-2", 20, 30)},
+			mocks:    []*models.Mock{mock("mock-1", 15), mock("mock-2", 25), mock("mock-3", 35)},
+			tcsMocks: [][]string{{"mock-1", "mock-3"}, {"mock-2"}},
+			isolated: []bool{true, true},
+		},
+		{
+			name: "testcases without timestamps and grpc testcases aren't isolated",
+			tcs: []*models.TestCase{
+				httpTc("test-1", 10, 20),
+				{Name: "test-2", Kind: models.HTTP},
+				{Name: "test-3", Kind: models.GRPC_EXPORT},
+			},
+			mocks:    []*models.Mock{mock("mock-1", 15), mock("mock-2", 25)},
+			tcsMocks: [][]string{{"mock-1"}, nil, nil},
+			shared:   []string{"mock-2"},
+			isolated: []bool{true, false, false},
+		},
+		{
+			name:     "mocks without timestamps are shared",
+			tcs:      []*models.TestCase{httpTc("test-1", 10, 20)},
+			mocks:    []*models.Mock{mock("mock-1", -1), mock("mock-2", 15)},
+			tcsMocks: [][]string{{"mock-2"}},
+			shared:   []string{"mock-1"},
+			isolated: []bool{true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcsMocks, shared, isolated := isolateTcsMocks(tt.tcs, tt.mocks)
+			var gotTcsMocks [][]string
+			for _, mocks := range tcsMocks {
+				gotTcsMocks = append(gotTcsMocks, names(mocks))
+			}
+			if !reflect.DeepEqual(gotTcsMocks, tt.tcsMocks) {
+				t.Errorf("tcsMocks = %v, want %v", gotTcsMocks, tt.tcsMocks)
+			}
+			if got := names(shared); !reflect.DeepEqual(got, tt.shared) {
+				t.Errorf("shared = %v, want %v", got, tt.shared)
+			}
+			if !reflect.DeepEqual(isolated, tt.isolated) {
+				t.Errorf("isolated = %v, want %v", isolated, tt.isolated)
+			}
+		})
+	}
+}
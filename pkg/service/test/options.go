@@ -0,0 +1,150 @@
+package test
+
+import (
+	"path/filepath"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+)
+
+// Option provides a means to configure the test run based on user input.
+type Option struct {
+	// Parallel is the number of testcases of a test set which are run concurrently.
+	Parallel uint
+	// ReportFormat is the format of the test reports, either yaml (default) or junit.
+	ReportFormat string
+	// Update rewrites the failing testcases with the actual responses of the application.
+	Update bool
+	// UpdatePrompt asks the user before updating every failing testcase.
+	UpdatePrompt bool
+	// Strict fails the testcases whose outgoing calls don't match the mocks, or which leave
+	// their mocks unused.
+	Strict bool
+	// Retries is the number of times a failing testcase is run again before it is marked failed.
+	Retries uint
+	// Quarantine keeps the failing flaky testcases from failing the test run.
+	Quarantine bool
+	// FreezeTime sends the recorded time of the testcases to the application in the
+	// KEPLOY_TEST_TIME header, for the SDKs to present it as the current time.
+	FreezeTime bool
+	// FakeTimeLib is the path of libfaketime, which is preloaded into the natively launched
+	// application to set its clock to the recorded time of every testcase.
+	FakeTimeLib string
+	// Readiness waits for the application to be ready instead of the fixed delay, when any of
+	// its checks is configured.
+	Readiness ReadinessProbe
+	// Load replays the testcases at a target rate after they are run, and writes the latencies
+	// of the responses in a load report.
+	Load LoadOptions
+	// CompareDefaultHeaders compares the headers of DefaultIgnoredHeaders as well in every test
+	// set, as they were compared before the default ignore list.
+	CompareDefaultHeaders bool
+	// Config is the test config of keploy.yaml, which is overridden by the config.yaml of the
+	// keploy directory.
+	Config *models.TestSetConfig
+	// TestSetConfigs are the test set configs of keploy.yaml, which override the config.yaml of
+	// the keploy directory and are overridden by the config.yaml of the test set.
+	TestSetConfigs map[string]models.TestSetConfig
+}
+
+// readTestSetConfig reads the config of the test set. The test config of keploy.yaml is
+// overridden by the config of the keploy directory, then by the test set config of keploy.yaml,
+// and then by the config of the test set.
+func readTestSetConfig(path, testSet string, opts Option) (*models.TestSetConfig, error) {
+	cfg := &models.TestSetConfig{}
+	if opts.Config != nil {
+		overrideConfig(cfg, opts.Config)
+	}
+	dirCfg, err := yaml.ReadTestSetConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	overrideConfig(cfg, dirCfg)
+	if projectCfg, ok := opts.TestSetConfigs[testSet]; ok {
+		overrideConfig(cfg, &projectCfg)
+	}
+	setCfg, err := yaml.ReadTestSetConfig(filepath.Join(path, testSet))
+	if err != nil {
+		return nil, err
+	}
+	overrideConfig(cfg, setCfg)
+	if opts.CompareDefaultHeaders {
+		headers := models.HeaderOptions{}
+		if cfg.Headers != nil {
+			headers = *cfg.Headers
+		}
+		headers.NoDefaults = true
+		cfg.Headers = &headers
+	}
+	// the header patterns are validated upfront, instead of failing every testcase
+	if _, err := newHeaderMatcher(cfg.Headers, nil); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// overrideConfig overrides the options of the config with the options set in the other config.
+func overrideConfig(cfg, with *models.TestSetConfig) {
+	if with.Noise != nil {
+		cfg.Noise = with.Noise
+	}
+	if with.Compare != nil {
+		cfg.Compare = with.Compare
+	}
+	if with.Headers != nil {
+		cfg.Headers = with.Headers
+	}
+	if with.Protobuf != nil {
+		cfg.Protobuf = with.Protobuf
+	}
+	if with.Stream != nil {
+		cfg.Stream = with.Stream
+	}
+	if with.Hooks != nil {
+		cfg.Hooks = with.Hooks
+	}
+	if with.Latency != nil {
+		cfg.Latency = with.Latency
+	}
+}
+
+// testNoise returns the noise of the testcase along with the noise of the test set config.
+func testNoise(tc *models.TestCase, cfg *models.TestSetConfig) []string {
+	if cfg == nil || len(cfg.Noise) == 0 {
+		return tc.Noise
+	}
+	noise := append([]string{}, tc.Noise...)
+	return append(noise, cfg.Noise...)
+}
+
+// compareOptions returns the options for comparing the response body of the testcase. The
+// options in the testcase override the options of the test set.
+func compareOptions(tc models.TestCase, cfg *models.TestSetConfig) models.MatchOptions {
+	switch {
+	case tc.Compare != nil:
+		return *tc.Compare
+	case cfg != nil && cfg.Compare != nil:
+		return *cfg.Compare
+	}
+	return models.MatchOptions{}
+}
+
+// hasExtractions checks whether any of the testcases passes the values of its response to the
+// later testcases, which requires the testcases to run in their order.
+func hasExtractions(tcs []*models.TestCase) bool {
+	for _, tc := range tcs {
+		if len(tc.Extract) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recordedTime returns the time at which the request of the testcase was recorded.
+func recordedTime(tc *models.TestCase) time.Time {
+	if tc.Kind == models.HTTP && !tc.HttpReq.Timestamp.IsZero() {
+		return tc.HttpReq.Timestamp
+	}
+	return time.Unix(tc.Created, 0)
+}
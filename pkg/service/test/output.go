@@ -17,25 +17,31 @@ import (
 const MAX_LINE_LENGTH = 50
 
 type DiffsPrinter struct {
-	testCase  string
-	statusExp string
-	statusAct string
-	headerExp string
-	headerAct string
-	bodyExp   string
-	bodyAct   string
-	bodyNoise []string
-	headNoise map[string]string
+	testCase   string
+	statusExp  string
+	statusAct  string
+	latencyExp string
+	latencyAct string
+	headerExp  string
+	headerAct  string
+	bodyExp    string
+	bodyAct    string
+	bodyNoise  []string
+	headNoise  map[string]string
 }
 
 func NewDiffsPrinter(testCase string) DiffsPrinter {
-	return DiffsPrinter{testCase, "", "", "", "", "", "", []string{}, map[string]string{}}
+	return DiffsPrinter{testCase, "", "", "", "", "", "", "", "", []string{}, map[string]string{}}
 }
 
 func (d *DiffsPrinter) PushStatusDiff(exp, act string) {
 	d.statusExp, d.statusAct = exp, act
 }
 
+func (d *DiffsPrinter) PushLatencyDiff(exp, act string) {
+	d.latencyExp, d.latencyAct = exp, act
+}
+
 func (d *DiffsPrinter) PushHeaderDiff(exp, act string, noise map[string]string) {
 	d.headerExp, d.headerAct, d.headNoise = exp, act, noise
 }
@@ -52,6 +58,10 @@ func (d *DiffsPrinter) Render() {
 		diffs = append(diffs, sprintDiff(d.statusExp, d.statusAct, "status"))
 	}
 
+	if d.latencyExp != d.latencyAct {
+		diffs = append(diffs, sprintDiff(d.latencyExp, d.latencyAct, "latency"))
+	}
+
 	if d.headerExp != d.headerAct {
 		diffs = append(diffs, sprintDiff(fmt.Sprint(d.headerExp), fmt.Sprint(d.headerAct), "header"))
 	}
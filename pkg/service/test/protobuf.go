@@ -0,0 +1,100 @@
+package test
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufComparator decodes the protobuf bodies with the descriptor set of the test set
+// config, and compares them as json. The message type is read from the "messageType" or
+// "proto" parameter of the content type, or else from the config.
+type protobufComparator struct{}
+
+var (
+	descriptorsMu sync.Mutex
+	// descriptors caches the descriptor sets by their paths
+	descriptors = map[string]*protoregistry.Files{}
+)
+
+func (protobufComparator) Compare(exp, act Body, opts BodyCompareOptions) (string, string, bool, error) {
+	if opts.Config == nil || opts.Config.Protobuf == nil || opts.Config.Protobuf.DescriptorSet == "" {
+		return "", "", false, fmt.Errorf("the descriptor set for decoding the protobuf body is not configured")
+	}
+	files, err := loadDescriptorSet(opts.Config.Protobuf.DescriptorSet)
+	if err != nil {
+		return "", "", false, err
+	}
+	expMsg, err := decodeProtobuf(exp, files, opts.Config.Protobuf.Message)
+	if err != nil {
+		return "", "", false, err
+	}
+	actMsg, err := decodeProtobuf(act, files, opts.Config.Protobuf.Message)
+	if err != nil {
+		return "", "", false, err
+	}
+	return Match(expMsg, actMsg, opts.Noise, opts.Match, opts.Logger)
+}
+
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	descriptorsMu.Lock()
+	defer descriptorsMu.Unlock()
+	if files, ok := descriptors[path]; ok {
+		return files, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the protobuf descriptor set. error: %v", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("failed to decode the protobuf descriptor set. error: %v", err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set. error: %v", err)
+	}
+	descriptors[path] = files
+	return files, nil
+}
+
+// decodeProtobuf decodes the protobuf body into json.
+func decodeProtobuf(body Body, files *protoregistry.Files, message string) (string, error) {
+	if _, params, err := mime.ParseMediaType(body.ContentType); err == nil {
+		for _, key := range []string{"messagetype", "proto"} {
+			if params[key] != "" {
+				message = params[key]
+				break
+			}
+		}
+	}
+	if message == "" {
+		return "", fmt.Errorf("the message type of the protobuf body is not known")
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(message))
+	if err != nil {
+		return "", fmt.Errorf("failed to find the protobuf message %s. error: %v", message, err)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return "", fmt.Errorf("%s is not a protobuf message", message)
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal([]byte(body.Data), msg); err != nil {
+		return "", fmt.Errorf("failed to decode the protobuf body as %s. error: %v", message, err)
+	}
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadinessProbe checks whether the user application is ready to serve the testcases, so that
+// the testcases start as soon as the application is up instead of after a fixed delay. All the
+// configured checks have to pass.
+type ReadinessProbe struct {
+	// HealthURL is polled until it responds with HealthStatus.
+	HealthURL    string
+	HealthStatus int
+	// TCPAddr is dialed until the application listens on it, e.g. "localhost:8080".
+	TCPAddr string
+	// LogPattern is matched against the lines written by the application on stdout and stderr.
+	LogPattern string
+	// Timeout is the number of seconds to wait for the application to be ready.
+	Timeout uint64
+}
+
+func (p ReadinessProbe) enabled() bool {
+	return p.HealthURL != "" || p.TCPAddr != "" || p.LogPattern != ""
+}
+
+// logWatcher matches the lines of the application logs against the pattern of the readiness
+// probe.
+type logWatcher struct {
+	pattern *regexp.Regexp
+	mu      sync.Mutex
+	line    []byte
+	matched bool
+}
+
+func (w *logWatcher) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.matched {
+		return len(p), nil
+	}
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if w.pattern.Match(w.line[:i]) {
+			w.matched = true
+			w.line = nil
+			return len(p), nil
+		}
+		w.line = w.line[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *logWatcher) ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// the last line may not be terminated yet
+	if !w.matched && len(w.line) > 0 && w.pattern.Match(w.line) {
+		w.matched = true
+	}
+	return w.matched
+}
+
+// waitForApp polls the checks of the readiness probe until all of them pass. The hosts of the
+// health url and the tcp address are replaced with the ip of the user container for docker.
+func (t *tester) waitForApp(probe ReadinessProbe, watcher *logWatcher, userIp string, isDocker bool) error {
+	healthURL, tcpAddr := probe.HealthURL, probe.TCPAddr
+	if isDocker {
+		if healthURL != "" {
+			healthURL = t.replaceHost(healthURL, userIp)
+		}
+		if _, port, err := net.SplitHostPort(tcpAddr); err == nil {
+			tcpAddr = net.JoinHostPort(userIp, port)
+		}
+	}
+	client := &http.Client{Timeout: time.Second}
+
+	timeout := time.Duration(probe.Timeout) * time.Second
+	deadline := time.Now().Add(timeout)
+	for {
+		var pending []string
+		if healthURL != "" {
+			resp, err := client.Get(healthURL)
+			if err != nil {
+				pending = append(pending, fmt.Sprintf("health url %s: %v", healthURL, err))
+			} else {
+				resp.Body.Close()
+				if resp.StatusCode != probe.HealthStatus {
+					pending = append(pending, fmt.Sprintf("health url %s responded with %d instead of %d", healthURL, resp.StatusCode, probe.HealthStatus))
+				}
+			}
+		}
+		if tcpAddr != "" {
+			conn, err := net.DialTimeout("tcp", tcpAddr, time.Second)
+			if err != nil {
+				pending = append(pending, fmt.Sprintf("tcp address %s: %v", tcpAddr, err))
+			} else {
+				conn.Close()
+			}
+		}
+		if watcher != nil && !watcher.ready() {
+			pending = append(pending, fmt.Sprintf("no log line matched %q", probe.LogPattern))
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the application is not ready after %v: %s", timeout, strings.Join(pending, "; "))
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.keploy.io/server/pkg"
+	"go.keploy.io/server/pkg/models"
+)
+
+// compareStream compares the events of the streamed responses one by one, with the noise and
+// the match options of the body applied to every event. The chunks of a chunked response are
+// compared as a whole when their count differs, since the chunks arriving together are merged
+// on replay. It returns the result of every event, along with the first mismatching event in
+// its canonical form for the diffs.
+func (t *tester) compareStream(tc models.TestCase, actual *models.HttpResp, bodyNoise []string, cfg *models.TestSetConfig) (bool, []models.BodyResult, string, string) {
+	exp, act := tc.HttpResp.StreamEvents, actual.StreamEvents
+	sse := pkg.IsEventStream(pkg.ToHttpHeader(tc.HttpResp.Header).Get("Content-Type"))
+	if !sse && len(exp) != len(act) {
+		exp, act = joinChunks(exp), joinChunks(act)
+	}
+
+	var tolerance int64
+	if cfg != nil && cfg.Stream != nil {
+		tolerance = cfg.Stream.TimingTolerance
+	}
+	opts := compareOptions(tc, cfg)
+
+	var (
+		pass               = true
+		results            []models.BodyResult
+		firstExp, firstAct string
+	)
+	for i := 0; i < len(exp) || i < len(act); i++ {
+		var expEvent, actEvent *models.HttpStreamEvent
+		if i < len(exp) {
+			expEvent = &exp[i]
+		}
+		if i < len(act) {
+			actEvent = &act[i]
+		}
+		cleanExp, cleanAct, ok := t.compareEvent(expEvent, actEvent, sse, bodyNoise, opts, tolerance)
+		results = append(results, models.BodyResult{
+			Normal:   ok,
+			Type:     models.BodyTypePlain,
+			Expected: cleanExp,
+			Actual:   cleanAct,
+		})
+		if !ok && pass {
+			pass = false
+			firstExp, firstAct = cleanExp, cleanAct
+		}
+	}
+	return pass, results, firstExp, firstAct
+}
+
+// compareEvent compares the events as json objects of their fields, and their delays when the
+// timing tolerance is set. A missing event is compared as null.
+func (t *tester) compareEvent(exp, act *models.HttpStreamEvent, sse bool, noise []string, opts models.MatchOptions, tolerance int64) (string, string, bool) {
+	expJson, actJson := eventJson(exp, sse), eventJson(act, sse)
+	if exp == nil || act == nil {
+		return expJson, actJson, false
+	}
+	cleanExp, cleanAct, ok, err := Match(expJson, actJson, noise, opts, t.logger)
+	if err != nil {
+		return expJson, actJson, false
+	}
+	if tolerance > 0 {
+		diff := exp.Delay - act.Delay
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			ok = false
+		}
+		cleanExp = fmt.Sprintf("%s (after %dms)", cleanExp, exp.Delay)
+		cleanAct = fmt.Sprintf("%s (after %dms)", cleanAct, act.Delay)
+	}
+	return cleanExp, cleanAct, ok
+}
+
+// eventJson converts the event into a json object of its fields. The fields of a Server-Sent
+// Event are its id, event, data and retry, and the chunk only has the data. The data which is
+// json is decoded, so that the noise can refer to its fields, e.g. "body.data.ts".
+func eventJson(e *models.HttpStreamEvent, sse bool) string {
+	if e == nil {
+		return "null"
+	}
+	fields := map[string]interface{}{}
+	if !sse {
+		fields["data"] = e.Data
+	} else {
+		var data []string
+		for _, line := range strings.Split(e.Data, "\n") {
+			// the lines starting with a colon are comments
+			if line == "" || strings.HasPrefix(line, ":") {
+				continue
+			}
+			name, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			if name == "data" {
+				data = append(data, value)
+				continue
+			}
+			fields[name] = value
+		}
+		fields["data"] = strings.Join(data, "\n")
+	}
+	var decoded interface{}
+	if text := fields["data"].(string); json.Valid([]byte(text)) && json.Unmarshal([]byte(text), &decoded) == nil {
+		fields["data"] = decoded
+	}
+	res, _ := json.Marshal(fields)
+	return string(res)
+}
+
+// joinChunks merges the chunks into one, delayed as the last chunk.
+func joinChunks(events []models.HttpStreamEvent) []models.HttpStreamEvent {
+	if len(events) == 0 {
+		return events
+	}
+	var data strings.Builder
+	for _, e := range events {
+		data.WriteString(e.Data)
+	}
+	return []models.HttpStreamEvent{{Data: data.String(), Delay: events[len(events)-1].Delay}}
+}
@@ -4,19 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	"net/url"
-
 	"github.com/k0kubun/pp/v3"
 	"github.com/wI2L/jsondiff"
 	"go.keploy.io/server/pkg"
 	"go.keploy.io/server/pkg/hooks"
 	"go.keploy.io/server/pkg/models"
 	"go.keploy.io/server/pkg/platform"
+	"go.keploy.io/server/pkg/platform/junit"
 	"go.keploy.io/server/pkg/platform/yaml"
 	"go.keploy.io/server/pkg/proxy"
 	"go.uber.org/zap"
@@ -27,6 +28,9 @@ var Emoji = "\U0001F430" + " Keploy:"
 type tester struct {
 	logger *zap.Logger
 	mutex  sync.Mutex
+	// promptMutex keeps the prompts of the concurrent testcases from interleaving, without
+	// blocking the other testcases while waiting for the answer
+	promptMutex sync.Mutex
 }
 
 func NewTester(logger *zap.Logger) Tester {
@@ -38,10 +42,19 @@ func NewTester(logger *zap.Logger) Tester {
 
 // func (t *tester) Test(tcsPath, mockPath, testReportPath string, pid uint32) bool {
 // func (t *tester) Test(tcsPath, mockPath, testReportPath string, appCmd, appContainer, appNetwork string, Delay uint64) bool {
-func (t *tester) Test(path, testReportPath string, appCmd, appContainer, appNetwork string, Delay uint64, passThorughPorts []uint, apiTimeout uint64) bool {
+func (t *tester) Test(path, testReportPath string, appCmd, appContainer, appNetwork string, Delay uint64, passThorughPorts []uint, apiTimeout uint64, opts Option) bool {
 	models.SetMode(models.MODE_TEST)
 
-	testReportFS := yaml.NewTestReportFS(t.logger)
+	var testReportFS yaml.TestReportFS
+	switch opts.ReportFormat {
+	case "", "yaml":
+		testReportFS = yaml.NewTestReportFS(t.logger)
+	case "junit":
+		testReportFS = junit.NewTestReportFS(t.logger)
+	default:
+		t.logger.Error("unsupported test report format, use yaml or junit", zap.Any("format", opts.ReportFormat))
+		return false
+	}
 	// fetch the recorded testcases with their mocks
 	// ys := yaml.NewYamlStore(tcsPath, mockPath, t.logger)
 	ys := yaml.NewYamlStore(path+"/tests", path, "", "", t.logger)
@@ -79,7 +92,7 @@ func (t *tester) Test(path, testReportPath string, appCmd, appContainer, appNetw
 	result := true
 
 	for _, sessionIndex := range sessions {
-		testRes := t.RunTestSet(sessionIndex, path, testReportPath, appCmd, appContainer, appNetwork, Delay, 0, ys, loadedHooks, testReportFS, nil, apiTimeout)
+		testRes := t.RunTestSet(sessionIndex, path, testReportPath, appCmd, appContainer, appNetwork, Delay, 0, ys, loadedHooks, testReportFS, nil, apiTimeout, opts)
 		result = result && testRes
 	}
 	t.logger.Info("test run completed", zap.Bool("passed overall", result))
@@ -93,7 +106,7 @@ func (t *tester) Test(path, testReportPath string, appCmd, appContainer, appNetw
 	return true
 }
 
-func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer, appNetwork string, delay uint64, pid uint32, ys platform.TestCaseDB, loadedHooks *hooks.Hook, testReportFS yaml.TestReportFS, testRunChan chan string, apiTimeout uint64) bool {
+func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer, appNetwork string, delay uint64, pid uint32, ys platform.TestCaseDB, loadedHooks *hooks.Hook, testReportFS yaml.TestReportFS, testRunChan chan string, apiTimeout uint64, opts Option) bool {
 
 	// Recover from panic and gracfully shutdown
 	defer loadedHooks.Recover(pkg.GenerateRandomID())
@@ -115,10 +128,64 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 		return false
 	}
 
+	cfg, err := readTestSetConfig(path, testSet, opts)
+	if err != nil {
+		t.logger.Error("failed to read the config of the test set", zap.Error(err), zap.Any("test-set", testSet))
+		return false
+	}
+
 	t.logger.Debug(fmt.Sprintf("the config mocks for %s are: %v\nthe testcase mocks are: %v", testSet, configMocks, tcsMocks))
 	loadedHooks.SetConfigMocks(configMocks)
 	loadedHooks.SetTcsMocks(tcsMocks)
 
+	if opts.FakeTimeLib != "" {
+		if isDockerCmd, _ := loadedHooks.IsDockerRelatedCmd(appCmd); isDockerCmd || appCmd == "" {
+			t.logger.Warn("the time of the application can only be faked when keploy launches it natively", zap.Any("test-set", testSet))
+		} else if err := loadedHooks.EnableFakeTime(opts.FakeTimeLib); err != nil {
+			t.logger.Error("failed to fake the time of the application", zap.Error(err))
+			return false
+		} else {
+			defer loadedHooks.DisableFakeTime()
+		}
+	}
+
+	var watcher *logWatcher
+	readiness := opts.Readiness
+	if readiness.LogPattern != "" {
+		pattern, err := regexp.Compile(readiness.LogPattern)
+		if err != nil {
+			t.logger.Error("invalid regex for the log line of the readiness probe", zap.Error(err))
+			return false
+		}
+		if len(appCmd) == 0 {
+			// the other probes, or the delay when there are none, wait for the application instead
+			t.logger.Warn("the logs of the application can only be watched when keploy launches it, ignoring the log line of the readiness probe", zap.Any("test-set", testSet))
+			readiness.LogPattern = ""
+		} else {
+			watcher = &logWatcher{pattern: pattern}
+			loadedHooks.SetAppLogWriter(watcher)
+			defer loadedHooks.SetAppLogWriter(nil)
+		}
+	}
+
+	// the setup hooks of the test set run before the application is launched
+	hookCfg := hookOptions(cfg)
+	preResults, preOk := t.runHooks("pre", hookCfg.Pre, testSet, "")
+	if !preOk {
+		t.logger.Error("failed to run the testcases since the pre hook of the test set failed", zap.Any("test-set", testSet))
+		err = testReportFS.Write(context.Background(), testReportPath, &models.TestReport{
+			Version: models.V1Beta1,
+			TestSet: testSet,
+			Total:   len(tcs),
+			Status:  string(models.TestRunStatusFailed),
+			Hooks:   preResults,
+		})
+		if err != nil {
+			t.logger.Error(err.Error())
+		}
+		return false
+	}
+
 	t.logger.Debug("", zap.Any("app pid", pid))
 	if len(appCmd) == 0 && pid != 0 {
 		t.logger.Debug("running keploy tests along with other unit tests")
@@ -126,6 +193,7 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 		// start user application
 		if err := loadedHooks.LaunchUserApplication(appCmd, appContainer, appNetwork, delay); err != nil {
 			t.logger.Debug("failed to process the user application")
+			t.runHooks("post", hookCfg.Post, testSet, "")
 			return false
 		}
 	}
@@ -134,8 +202,17 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 	testReport := &models.TestReport{
 		Version: models.V1Beta1,
 		// Name:    runId,
-		Total:  len(tcs),
-		Status: string(models.TestRunStatusRunning),
+		TestSet: testSet,
+		Total:   len(tcs),
+		Status:  string(models.TestRunStatusRunning),
+		Hooks:   preResults,
+	}
+
+	// runPostHooks runs the teardown hooks of the test set and adds their results to the report
+	runPostHooks := func() bool {
+		postResults, ok := t.runHooks("post", hookCfg.Post, testSet, "")
+		testReport.Hooks = append(testReport.Hooks, postResults...)
+		return ok
 	}
 
 	// starts the testrun
@@ -151,11 +228,19 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 	}
 
 	var (
-		success = 0
-		failure = 0
-		status  = models.TestRunStatusPassed
+		success     = 0
+		failure     = 0
+		quarantined = 0
+		status      = models.TestRunStatusPassed
 	)
 
+	// history stores the statuses of the testcases in the previous test runs
+	history, err := yaml.ReadTestHistory(testReportPath)
+	if err != nil {
+		t.logger.Warn("failed to read the history of the test runs, flaky testcases are only detected by retries", zap.Error(err))
+		history = &models.TestHistory{TestSets: map[string]map[string][]models.TestStatus{}}
+	}
+
 	passed := true
 
 	// sort the testcases in
@@ -185,124 +270,160 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 	}
 
 	t.logger.Info("", zap.Any("no of test cases", len(tcs)), zap.Any("test-set", testSet))
-	t.logger.Debug(fmt.Sprintf("the delay is %v", time.Duration(time.Duration(delay)*time.Second)))
-
-	// added delay to hold running keploy tests until application starts
-	time.Sleep(time.Duration(delay) * time.Second)
-	for _, tc := range tcs {
-		switch tc.Kind {
-		case models.HTTP:
-			// httpSpec := &spec.HttpSpec{}
-			// err := tc.Spec.Decode(httpSpec)
-			// if err != nil {
-			// 	t.logger.Error("failed to unmarshal yaml doc for simulation of http request", zap.Error(err))
-			// 	return false
-			// }
-			started := time.Now().UTC()
-			// for i, _ := range mocks[tc.Name] {
-			// 	loadedHooks.AppendDeps(&mocks[tc.Name][i])
-			// }
+	if readiness.enabled() {
+		t.logger.Debug("waiting for the application to be ready", zap.Any("probe", readiness))
+		err = t.waitForApp(readiness, watcher, userIp, ok || dIDE)
+		if err != nil {
+			t.logger.Error("failed to run the testcases since the application is not ready", zap.Error(err), zap.Any("test-set", testSet))
+			testReport.Status = string(models.TestRunStatusFailed)
+			runPostHooks()
+			if err := testReportFS.Write(context.Background(), testReportPath, testReport); err != nil {
+				t.logger.Error(err.Error())
+			}
+			if !(len(appCmd) == 0 && pid != 0) {
+				loadedHooks.StopUserApplication()
+			}
+			return false
+		}
+		t.logger.Info("the application is ready", zap.Any("test-set", testSet))
+	} else {
+		t.logger.Debug(fmt.Sprintf("the delay is %v", time.Duration(time.Duration(delay)*time.Second)))
 
-			// t.logger.Debug("Before setting deps.... during testing...")
-			// loadedHooks.SetDeps(tc.Mocks)
-			t.logger.Debug("Before simulating the request", zap.Any("Test case", tc))
+		// added delay to hold running keploy tests until application starts
+		time.Sleep(time.Duration(delay) * time.Second)
+	}
 
-			ok, _ := loadedHooks.IsDockerRelatedCmd(appCmd)
-			if ok || dIDE {
-				//changing Ip address only in case of docker
-				tc.HttpReq.URL = replaceHostToIP(tc.HttpReq.URL, userIp)
-				t.logger.Debug("", zap.Any("replaced URL in case of docker env", tc.HttpReq.URL))
+	// setResult stores the result of a testcase in the test report
+	setResult := func(testResult *models.TestResult) {
+		if isFlaky(history.TestSets[testSet][testResult.TestCaseID]) {
+			testResult.Flaky = true
+		}
+		addToHistory(history, testSet, testResult.TestCaseID, testResult.Status)
+		switch {
+		case testResult.Status == models.TestStatusPassed:
+			success++
+		case testResult.Flaky && opts.Quarantine:
+			testResult.Quarantined = true
+			quarantined++
+			t.logger.Warn("the flaky testcase failed and is quarantined", zap.Any("testcase id", testResult.TestCaseID))
+		default:
+			passed = false
+			failure++
+			status = models.TestRunStatusFailed
+		}
+		testReportFS.Lock()
+		testReportFS.SetResult(testReport.Name, *testResult)
+	}
+
+	run := &testSetRun{
+		path:           path,
+		testSet:        testSet,
+		testReportName: testReport.Name,
+		userIp:         userIp,
+		isDocker:       ok || dIDE,
+		apiTimeout:     apiTimeout,
+		cfg:            cfg,
+		opts:           opts,
+		hooks:          loadedHooks,
+		vars:           pkg.SecretVars(),
+	}
+	if opts.Parallel > 1 && hasExtractions(tcs) {
+		t.logger.Warn("running the testcases sequentially since they pass the values of their responses to the later testcases", zap.Any("test-set", testSet))
+		opts.Parallel = 1
+	}
+	if opts.Parallel > 1 && opts.FakeTimeLib != "" {
+		t.logger.Warn("running the testcases sequentially since the application has a single clock", zap.Any("test-set", testSet))
+		opts.Parallel = 1
+	}
+	if opts.Strict {
+		// the outgoing calls can't be attributed to the testcases running concurrently
+		if opts.Parallel > 1 {
+			t.logger.Warn("running the testcases sequentially to verify their outgoing calls in strict mode", zap.Any("test-set", testSet))
+		}
+		opts.Parallel = 1
+		loadedHooks.RecordDepCalls(true)
+		defer loadedHooks.RecordDepCalls(false)
+	}
+
+	// sequential are the testcases whose mocks aren't isolated, which run one at a time
+	sequential := tcs
+	if opts.Parallel > 1 || opts.Strict {
+		tcsMocksOfTests, sharedMocks, isolated := isolateTcsMocks(tcs, tcsMocks)
+		sequential = nil
+		var sequentialNames []string
+		for i, tc := range tcs {
+			if !isolated[i] {
+				sequential = append(sequential, tc)
+				sequentialNames = append(sequentialNames, tc.Name)
+			}
+		}
+		if len(sequential) > 0 {
+			if opts.Parallel > 1 {
+				t.logger.Warn("running the testcases sequentially since their mocks can't be isolated. The testcases are gRPC testcases or are recorded without timestamps", zap.Any("test-set", testSet), zap.Any("testcases", sequentialNames))
+				testReport.Sequential = sequentialNames
 			}
-			t.logger.Debug(fmt.Sprintf("the url of the testcase: %v", tc.HttpReq.URL))
-			// time.Sleep(10 * time.Second)
-			resp, err := pkg.SimulateHttp(*tc, t.logger, apiTimeout)
-			t.logger.Debug("After simulating the request", zap.Any("test case id", tc.Name))
-			t.logger.Debug("After GetResp of the request", zap.Any("test case id", tc.Name))
+			if opts.Strict {
+				t.logger.Warn("the unused mocks of the testcases can't be verified since their mocks can't be isolated", zap.Any("test-set", testSet), zap.Any("testcases", sequentialNames))
+			}
+		}
 
-			if err != nil {
-				t.logger.Info("result", zap.Any("testcase id", tc.Name), zap.Any("passed", "false"))
+		// every testcase can only use the mocks recorded during its own execution along with
+		// the shared mocks, so that the concurrent testcases don't consume each other's mocks.
+		loadedHooks.SetTcsMocks(sharedMocks)
+		t.logger.Debug(fmt.Sprintf("running %d testcases concurrently", opts.Parallel), zap.Any("test-set", testSet))
+
+		var (
+			results = make([]*models.TestResult, len(tcs))
+			sem     = make(chan struct{}, opts.Parallel)
+			wg      sync.WaitGroup
+		)
+		for i, tc := range tcs {
+			if !isolated[i] {
 				continue
 			}
-			// println("before blocking simulate")
-
-			// resp := loadedHooks.GetResp()
-			// println("after blocking simulate")
-			testPass, testResult := t.testHttp(*tc, resp)
-			passed = passed && testPass
-			t.logger.Info("result", zap.Any("testcase id", tc.Name), zap.Any("passed", testPass))
-			testStatus := models.TestStatusPending
-			if testPass {
-				testStatus = models.TestStatusPassed
-				success++
-			} else {
-				testStatus = models.TestStatusFailed
-				failure++
-				status = models.TestRunStatusFailed
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int, tc *models.TestCase) {
+				// Recover from panic and gracefully shutdown
+				defer loadedHooks.Recover(pkg.GenerateRandomID())
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				results[i] = t.runTestCase(run, tc, tcsMocksOfTests[i], true)
+			}(i, tc)
+		}
+		wg.Wait()
+
+		// the results are stored in the order of the testcases to keep the test report deterministic
+		for _, testResult := range results {
+			if testResult != nil {
+				setResult(testResult)
 			}
+		}
+	}
 
-			testReportFS.Lock()
-			testReportFS.SetResult(testReport.Name, models.TestResult{
-				Kind:       models.HTTP,
-				Name:       testReport.Name,
-				Status:     testStatus,
-				Started:    started.Unix(),
-				Completed:  time.Now().UTC().Unix(),
-				TestCaseID: tc.Name,
-				Req: models.HttpReq{
-					Method:     tc.HttpReq.Method,
-					ProtoMajor: tc.HttpReq.ProtoMajor,
-					ProtoMinor: tc.HttpReq.ProtoMinor,
-					URL:        tc.HttpReq.URL,
-					URLParams:  tc.HttpReq.URLParams,
-					Header:     tc.HttpReq.Header,
-					Body:       tc.HttpReq.Body,
-				},
-				Res: models.HttpResp{
-					StatusCode:    tc.HttpResp.StatusCode,
-					Header:        tc.HttpResp.Header,
-					Body:          tc.HttpResp.Body,
-					StatusMessage: tc.HttpResp.StatusMessage,
-					ProtoMajor:    tc.HttpResp.ProtoMajor,
-					ProtoMinor:    tc.HttpResp.ProtoMinor,
-				},
-				// Mocks:        httpSpec.Mocks,
-				// TestCasePath: tcsPath,
-				TestCasePath: path,
-				// MockPath:     mockPath,
-				// Noise:        httpSpec.Assertions["noise"],
-				Noise:  tc.Noise,
-				Result: *testResult,
-			})
-			testReportFS.Lock()
-			testReportFS.Unlock()
-			// 		spec := &spec.HttpSpec{}
-			// 		err := tc.Spec.Decode(spec)
-			// 		if err!=nil {
-			// 			t.logger.Error("failed to unmarshal yaml doc for simulation of http request", zap.Error(err))
-			// 			return false
-			// 		}
-			// 		req, err := http.NewRequest(string(spec.Request.Method), "http://localhost"+":"+k.cfg.App.Port+spec.Request.URL, bytes.NewBufferString(spec.Request.Body))
-			// 		if err != nil {
-			// 			panic(err)
-			// 		}
-			// 		req.Header = tc.HttpReq.Header
-			// 		req.Header.Set("KEPLOY_TEST_ID", tc.ID)
-			// 		req.ProtoMajor = tc.HttpReq.ProtoMajor
-			// 		req.ProtoMinor = tc.HttpReq.ProtoMinor
-			// 		req.Close = true
-
-			// 		// httpresp, err := k.client.Do(req)
-			// 		k.client.Do(req)
-			// 		if err != nil {
-			// 			k.Log.Error("failed sending testcase request to app", zap.Error(err))
-			// 			return nil, err
-			// 		}
-			// 		// defer httpresp.Body.Close()
-			// 		println("before blocking simulate")
+	for _, tc := range sequential {
+		if testResult := t.runTestCase(run, tc, nil, false); testResult != nil {
+			setResult(testResult)
+		}
+	}
 
+	if opts.Load.Enabled {
+		loadReport := t.runLoad(run, tcs, tcsMocks)
+		err = yaml.WriteLoadReport(testReportPath, loadReport)
+		if err != nil {
+			t.logger.Error("failed to write the load report", zap.Error(err), zap.Any("test-set", testSet))
+		} else {
+			t.logger.Info("load run completed", zap.Any("test-set", testSet), zap.Any("requests", loadReport.Sent), zap.Any("p99 latency (ms)", loadReport.Total.Latency.P99), zap.Any("error rate", loadReport.Total.ErrorRate))
 		}
 	}
 
+	if !runPostHooks() {
+		t.logger.Error("the post hook of the test set failed", zap.Any("test-set", testSet))
+		passed = false
+		status = models.TestRunStatusFailed
+	}
+
 	// store the result of the testrun as test-report
 	testResults, err := testReportFS.GetResults(testReport.Name)
 	if err != nil {
@@ -314,12 +435,22 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 	testReport.Tests = testResults
 	testReport.Success = success
 	testReport.Failure = failure
+	testReport.Quarantined = quarantined
 	err = testReportFS.Write(context.Background(), testReportPath, testReport)
 	if err != nil {
 		t.logger.Error(err.Error())
 		return true
 	}
 
+	err = yaml.WriteTestHistory(testReportPath, history)
+	if err != nil {
+		t.logger.Error("failed to write the history of the test runs", zap.Error(err))
+	}
+
+	if len(run.updated) > 0 {
+		t.logger.Info("updated the failing testcases with the actual responses", zap.Any("test-set", testSet), zap.Any("testcases", run.updated))
+	}
+
 	t.logger.Debug("the result before", zap.Any("", result), zap.Any("testreport name", testReport.Name))
 	result = result && passed
 	t.logger.Debug("the result after", zap.Any("", result), zap.Any("testreport name", testReport.Name))
@@ -334,7 +465,237 @@ func (t *tester) RunTestSet(testSet, path, testReportPath, appCmd, appContainer,
 	return result
 }
 
-func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (bool, *models.Result) {
+// testSetRun holds the context shared by the testcases of a test set during a test run.
+type testSetRun struct {
+	path           string
+	testSet        string
+	testReportName string
+	userIp         string
+	isDocker       bool
+	apiTimeout     uint64
+	cfg            *models.TestSetConfig
+	opts           Option
+	hooks          *hooks.Hook
+	// updated lists the files of the testcases updated with the actual responses
+	updated []string
+	// vars stores the values extracted from the responses of the earlier testcases, along with
+	// the redacted secrets passed in the environment
+	vars map[string]string
+}
+
+// runTestCase runs the testcase, and runs it again up to the number of retries while it fails.
+// With isolated mocks, the testcase can only use its own mocks along with the shared mocks.
+// Otherwise, the mocks consumed by a failed attempt are restored for the next attempt.
+func (t *tester) runTestCase(run *testSetRun, tc *models.TestCase, tcMocks []*models.Mock, isolated bool) *models.TestResult {
+	var result *models.TestResult
+	for attempt := uint(1); attempt <= run.opts.Retries+1; attempt++ {
+		lastAttempt := attempt == run.opts.Retries+1
+
+		var tcsMocks []*models.Mock
+		if isolated {
+			run.hooks.AddMockSet(tc.Name, tcMocks)
+		} else {
+			tcsMocks = run.hooks.GetTcsMocks()
+		}
+		if run.opts.Strict {
+			// drop the outgoing calls made in between the testcases
+			run.hooks.FetchDepCalls()
+		}
+
+		result = t.testCaseWithHooks(run, tc, lastAttempt)
+
+		// remove the mocks which are not consumed by the testcase
+		var unusedMocks []*models.Mock
+		if isolated {
+			unusedMocks = run.hooks.RemoveMockSet(tc.Name)
+		}
+		if run.opts.Strict && result != nil {
+			t.verifyDeps(result, run.hooks.FetchDepCalls(), unusedMocks)
+		}
+
+		if result != nil {
+			result.Attempts = int(attempt)
+			// the testcase flipped from fail to pass in the same run
+			result.Flaky = result.Status == models.TestStatusPassed && attempt > 1
+			if result.Status == models.TestStatusPassed {
+				return result
+			}
+		}
+		if lastAttempt {
+			break
+		}
+		t.logger.Info("retrying the failed testcase", zap.Any("testcase id", tc.Name), zap.Any("attempt", attempt+1))
+		if !isolated {
+			run.hooks.SetTcsMocks(tcsMocks)
+		}
+	}
+	return result
+}
+
+// testCase simulates the testcase against the user application and compares the response.
+// It returns nil if the testcase could not be simulated. The failing testcase is only updated
+// with the actual response on its last attempt.
+func (t *tester) testCase(run *testSetRun, tc *models.TestCase, lastAttempt bool) *models.TestResult {
+	started := time.Now().UTC()
+	t.logger.Debug("Before simulating the request", zap.Any("Test case", tc))
+
+	if err := run.hooks.SetFakeTime(recordedTime(tc)); err != nil {
+		t.logger.Warn("failed to set the time of the application to the recorded time", zap.Error(err), zap.Any("testcase id", tc.Name))
+	}
+
+	var (
+		testPass   bool
+		testResult *models.Result
+		result     = &models.TestResult{
+			Kind:         tc.Kind,
+			Name:         run.testReportName,
+			TestCaseID:   tc.Name,
+			TestCasePath: run.path,
+			Noise:        testNoise(tc, run.cfg),
+		}
+	)
+
+	switch tc.Kind {
+	case models.HTTP:
+		// the recorded testcase is kept as it is, since it may be updated with the actual response
+		simTc := *tc
+		if run.isDocker {
+			//changing Ip address only in case of docker
+			simTc.HttpReq.URL = t.replaceHost(tc.HttpReq.URL, run.userIp)
+			t.logger.Debug("", zap.Any("replaced URL in case of docker env", simTc.HttpReq.URL))
+		}
+		if run.opts.FreezeTime {
+			header := map[string]string{}
+			for key, value := range tc.HttpReq.Header {
+				header[key] = value
+			}
+			header[models.KTimeHeader] = recordedTime(tc).Format(time.RFC3339Nano)
+			simTc.HttpReq.Header = header
+		}
+		t.logger.Debug(fmt.Sprintf("the url of the testcase: %v", simTc.HttpReq.URL))
+		resp, err := pkg.SimulateHttp(simTc, run.vars, t.logger, run.apiTimeout)
+		t.logger.Debug("After simulating the request", zap.Any("test case id", tc.Name))
+		if err != nil {
+			t.logger.Info("result", zap.Any("testcase id", tc.Name), zap.Any("passed", "false"))
+			return nil
+		}
+
+		// the expected response may refer to the values extracted from the earlier testcases
+		expTc := *tc
+		expTc.Noise = result.Noise
+		expTc.HttpResp.Header = pkg.SubstituteHeaderVars(tc.HttpResp.Header, run.vars)
+		expTc.HttpResp.Body = pkg.SubstituteVars(tc.HttpResp.Body, run.vars)
+		if tc.HttpResp.Binary != "" {
+			body, err := pkg.DecodeBody("", tc.HttpResp.Binary)
+			if err != nil {
+				t.logger.Error("failed to decode the binary response body of the testcase", zap.Error(err), zap.Any("testcase id", tc.Name))
+				return nil
+			}
+			expTc.HttpResp.Body = string(body)
+		}
+		testPass, testResult = t.testHttp(expTc, resp, run.cfg)
+
+		if !testPass && run.opts.Update && lastAttempt {
+			updated := *tc
+			updated.HttpResp.StatusCode = resp.StatusCode
+			updated.HttpResp.StatusMessage = http.StatusText(resp.StatusCode)
+			updated.HttpResp.Header = resp.Header
+			updated.HttpResp.Body, updated.HttpResp.Binary = pkg.EncodeBody([]byte(resp.Body))
+			updated.HttpResp.StreamEvents = resp.StreamEvents
+			updated.HttpResp.Latency = resp.Latency
+			t.updateTestCase(run, &updated)
+		}
+		result.Req = models.HttpReq{
+			Method:     tc.HttpReq.Method,
+			ProtoMajor: tc.HttpReq.ProtoMajor,
+			ProtoMinor: tc.HttpReq.ProtoMinor,
+			URL:        pkg.SubstituteVars(simTc.HttpReq.URL, run.vars),
+			URLParams:  tc.HttpReq.URLParams,
+			Header:     pkg.SubstituteHeaderVars(tc.HttpReq.Header, run.vars),
+			Body:       pkg.SubstituteVars(tc.HttpReq.Body, run.vars),
+		}
+		result.Res = models.HttpResp{
+			StatusCode:    tc.HttpResp.StatusCode,
+			Header:        expTc.HttpResp.Header,
+			Body:          expTc.HttpResp.Body,
+			StatusMessage: tc.HttpResp.StatusMessage,
+			ProtoMajor:    tc.HttpResp.ProtoMajor,
+			ProtoMinor:    tc.HttpResp.ProtoMinor,
+		}
+		// the later testcases can refer to the values of this response
+		if len(tc.Extract) > 0 {
+			err = pkg.ExtractVars(tc.Extract, resp, run.vars)
+			if err != nil {
+				t.logger.Warn("failed to extract the values from the response of the testcase", zap.Error(err), zap.Any("testcase id", tc.Name))
+			}
+		}
+	case models.GRPC_EXPORT:
+		simTc := *tc
+		if run.isDocker {
+			//changing Ip address only in case of docker
+			pseudoHeaders := map[string]string{}
+			for key, value := range tc.GrpcReq.Headers.PseudoHeaders {
+				pseudoHeaders[key] = value
+			}
+			pseudoHeaders[":authority"] = strings.TrimPrefix(t.replaceHost("http://"+pseudoHeaders[":authority"], run.userIp), "http://")
+			simTc.GrpcReq.Headers.PseudoHeaders = pseudoHeaders
+			t.logger.Debug("", zap.Any("replaced authority in case of docker env", pseudoHeaders[":authority"]))
+		}
+		if run.opts.FreezeTime {
+			headers := map[string]string{}
+			for key, value := range tc.GrpcReq.Headers.OrdinaryHeaders {
+				headers[key] = value
+			}
+			// the metadata keys of gRPC are lowercase
+			headers[strings.ToLower(models.KTimeHeader)] = recordedTime(tc).Format(time.RFC3339Nano)
+			simTc.GrpcReq.Headers.OrdinaryHeaders = headers
+		}
+		resp, err := simulateGrpc(simTc, t.logger, run.apiTimeout)
+		t.logger.Debug("After simulating the request", zap.Any("test case id", tc.Name))
+		if err != nil {
+			t.logger.Info("result", zap.Any("testcase id", tc.Name), zap.Any("passed", "false"))
+			return nil
+		}
+
+		expTc := *tc
+		expTc.Noise = result.Noise
+		testPass, testResult = t.testGrpc(expTc, resp, run.cfg)
+		if !testPass && run.opts.Update && lastAttempt {
+			updated := *tc
+			updated.GrpcResp = *resp
+			t.updateTestCase(run, &updated)
+		}
+		result.GrpcReq = tc.GrpcReq
+		result.GrpcRes = tc.GrpcResp
+	default:
+		t.logger.Debug("skipping the testcase of unsupported kind", zap.Any("testcase id", tc.Name), zap.Any("kind", tc.Kind))
+		return nil
+	}
+
+	t.logger.Info("result", zap.Any("testcase id", tc.Name), zap.Any("passed", testPass))
+	result.Status = models.TestStatusFailed
+	if testPass {
+		result.Status = models.TestStatusPassed
+	}
+	completed := time.Now().UTC()
+	result.Started = started.Unix()
+	result.Completed = completed.Unix()
+	result.Duration = completed.Sub(started).Milliseconds()
+	result.Result = *testResult
+	return result
+}
+
+// replaceHost replaces the host of the url with the ip of the user container, and keeps the
+// url as it is if it can't be replaced.
+func (t *tester) replaceHost(currentURL, userIp string) string {
+	replaced, err := pkg.ReplaceHostToIP(currentURL, userIp)
+	if err != nil {
+		t.logger.Warn("failed to replace the host of the url with the ip of the user container, keeping the url as it is", zap.Any("url", currentURL), zap.Error(err))
+	}
+	return replaced
+}
+
+func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp, cfg *models.TestSetConfig) (bool, *models.Result) {
 	// httpSpec := &spec.HttpSpec{}
 	bodyType := models.BodyTypePlain
 	if json.Valid([]byte(actualResponse.Body)) {
@@ -394,23 +755,57 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 	// stores the json body after removing the noise
 	cleanExp, cleanAct := "", ""
 	var err error
-	if !Contains(noise, "body") && bodyType == models.BodyTypeJSON {
-		cleanExp, cleanAct, pass, err = Match(tc.HttpResp.Body, actualResponse.Body, bodyNoise, t.logger)
+	// the other structured bodies are compared by the comparator of their content type
+	comparator := bodyComparatorFor(pkg.ToHttpHeader(tc.HttpResp.Header).Get("Content-Type"))
+	stream := len(tc.HttpResp.StreamEvents) > 0
+	if stream {
+		// the streamed responses are compared event by event
+		var results []models.BodyResult
+		if !Contains(noise, "body") {
+			pass, results, cleanExp, cleanAct = t.compareStream(tc, actualResponse, bodyNoise, cfg)
+		}
+		if len(results) > 0 {
+			res.BodyResult = results
+		} else {
+			res.BodyResult[0].Normal = pass
+		}
+	} else if !Contains(noise, "body") && bodyType == models.BodyTypeJSON {
+		cleanExp, cleanAct, pass, err = Match(tc.HttpResp.Body, actualResponse.Body, bodyNoise, compareOptions(tc, cfg), t.logger)
 		if err != nil {
 			return false, res
 		}
 		// debug log for cleanExp and cleanAct
 		t.logger.Debug("cleanExp", zap.Any("", cleanExp))
 		t.logger.Debug("cleanAct", zap.Any("", cleanAct))
+	} else if !Contains(noise, "body") && comparator != nil {
+		exp := Body{ContentType: pkg.ToHttpHeader(tc.HttpResp.Header).Get("Content-Type"), Data: tc.HttpResp.Body}
+		act := Body{ContentType: pkg.ToHttpHeader(actualResponse.Header).Get("Content-Type"), Data: actualResponse.Body}
+		cleanExp, cleanAct, pass, err = comparator.Compare(exp, act, BodyCompareOptions{
+			Noise:  bodyNoise,
+			Match:  compareOptions(tc, cfg),
+			Config: cfg,
+			Logger: t.logger,
+		})
+		if err != nil {
+			t.logger.Warn("failed to compare the bodies by their content type, comparing them exactly", zap.Error(err), zap.Any("testcase id", tc.Name))
+			comparator = nil
+			pass = tc.HttpResp.Body == actualResponse.Body
+		}
 	} else {
 		if !Contains(noise, "body") && tc.HttpResp.Body != actualResponse.Body {
 			pass = false
 		}
 	}
 
-	res.BodyResult[0].Normal = pass
+	if !stream {
+		res.BodyResult[0].Normal = pass
+	}
 
-	if !CompareHeaders(pkg.ToHttpHeader(tc.HttpResp.Header), pkg.ToHttpHeader(actualResponse.Header), hRes, headerNoise) {
+	expHeader, actHeader := pkg.ToHttpHeader(tc.HttpResp.Header), pkg.ToHttpHeader(actualResponse.Header)
+	if !t.matchHeaders(cfg, expHeader, actHeader, hRes, headerNoise) {
+		pass = false
+	}
+	if !CompareHeaders(expHeader, actHeader, hRes, headerNoise) {
 
 		pass = false
 	}
@@ -423,6 +818,18 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 		pass = false
 	}
 
+	// the timings of the streamed responses are compared event by event
+	if !stream && cfg != nil {
+		res.Latency = compareLatency(tc, actualResponse, cfg.Latency)
+		if res.Latency != nil && !res.Latency.Normal {
+			if cfg.Latency.WarnOnly {
+				t.logger.Warn("the response of the testcase is slower than the latency limit", zap.Any("testcase id", tc.Name), zap.Any("recorded latency (ms)", res.Latency.Expected), zap.Any("actual latency (ms)", res.Latency.Actual), zap.Any("limit (ms)", res.Latency.Limit))
+			} else {
+				pass = false
+			}
+		}
+	}
+
 	if !pass {
 		logDiffs := NewDiffsPrinter(tc.Name)
 
@@ -443,6 +850,10 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 			logDiffs.PushStatusDiff(fmt.Sprint(res.StatusCode.Expected), fmt.Sprint(res.StatusCode.Actual))
 		}
 
+		if res.Latency != nil && !res.Latency.Normal {
+			logDiffs.PushLatencyDiff(fmt.Sprintf("%dms (limit %dms)", res.Latency.Expected, res.Latency.Limit), fmt.Sprintf("%dms", res.Latency.Actual))
+		}
+
 		var (
 			actualHeader   = map[string][]string{}
 			expectedHeader = map[string][]string{}
@@ -463,7 +874,12 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 			}
 		}
 
-		if !res.BodyResult[0].Normal {
+		if stream {
+			// the first mismatching event is shown
+			if cleanExp != "" || cleanAct != "" {
+				logDiffs.PushBodyDiff(cleanExp, cleanAct, bodyNoise)
+			}
+		} else if !res.BodyResult[0].Normal {
 
 			if json.Valid([]byte(actualResponse.Body)) {
 				patch, err := jsondiff.Compare(cleanExp, cleanAct)
@@ -478,6 +894,8 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 					logDiffs.PushBodyDiff(fmt.Sprint(op.OldValue), fmt.Sprint(op.Value), bodyNoise)
 
 				}
+			} else if comparator != nil {
+				logDiffs.PushBodyDiff(cleanExp, cleanAct, bodyNoise)
 			} else {
 				logDiffs.PushBodyDiff(fmt.Sprint(tc.HttpResp.Body), fmt.Sprint(actualResponse.Body), bodyNoise)
 			}
@@ -504,23 +922,3 @@ func (t *tester) testHttp(tc models.TestCase, actualResponse *models.HttpResp) (
 
 	return pass, res
 }
-
-func replaceHostToIP(currentURL string, ipAddress string) string {
-	// Parse the current URL
-	parsedURL, err := url.Parse(currentURL)
-	if err != nil {
-		// Return the original URL if parsing fails
-		return currentURL
-	}
-
-	if ipAddress == "" {
-		fmt.Errorf(Emoji, "failed to replace url in case of docker env")
-		return currentURL
-	}
-
-	// Replace hostname with the IP address
-	parsedURL.Host = strings.Replace(parsedURL.Host, parsedURL.Hostname(), ipAddress, 1)
-
-	// Return the modified URL
-	return parsedURL.String()
-}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/pkg/models"
+	"go.keploy.io/server/pkg/platform/yaml"
+	"go.uber.org/zap"
+)
+
+// updateTestCase rewrites the yaml of the failing testcase with its actual response. The
+// noise of the testcase is kept as it is. When prompting is enabled, the testcase is only
+// updated if the user accepts it.
+func (t *tester) updateTestCase(run *testSetRun, tc *models.TestCase) {
+	if run.opts.UpdatePrompt {
+		t.promptMutex.Lock()
+		fmt.Printf("Update the testcase %s of %s with the actual response? [y/N]: ", tc.Name, run.testSet)
+		var answer string
+		fmt.Scanln(&answer)
+		t.promptMutex.Unlock()
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			t.logger.Info("skipped updating the testcase", zap.Any("testcase id", tc.Name))
+			return
+		}
+	}
+
+	tcsPath := filepath.Join(run.path, run.testSet, "tests")
+	ys := yaml.NewYamlStore(tcsPath, filepath.Join(run.path, run.testSet), "", "", t.logger)
+	err := ys.WriteTestcase(tc)
+	if err != nil {
+		t.logger.Error("failed to update the testcase with the actual response", zap.Error(err), zap.Any("testcase id", tc.Name))
+		return
+	}
+
+	t.mutex.Lock()
+	run.updated = append(run.updated, filepath.Join(tcsPath, tc.Name+".yaml"))
+	t.mutex.Unlock()
+}
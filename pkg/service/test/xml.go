@@ -0,0 +1,158 @@
+package test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlComparator compares the xml bodies in their canonical form, where the attributes are
+// sorted and the whitespace between the elements is dropped. The noisy fields are XPaths, e.g.
+// "/order/created", "//timestamp", "/order/@id" or "/order/note/text()".
+type xmlComparator struct{}
+
+func (xmlComparator) Compare(exp, act Body, opts BodyCompareOptions) (string, string, bool, error) {
+	expDoc, err := parseXml(exp.Data)
+	if err != nil {
+		return "", "", false, err
+	}
+	actDoc, err := parseXml(act.Data)
+	if err != nil {
+		return "", "", false, err
+	}
+	for _, path := range opts.Noise {
+		expDoc.removePath(path)
+		actDoc.removePath(path)
+	}
+	cleanExp, cleanAct := expDoc.String(), actDoc.String()
+	return cleanExp, cleanAct, cleanExp == cleanAct, nil
+}
+
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+}
+
+// parseXml parses the xml body into a document node, whose children are the root elements.
+func parseXml(data string) (*xmlNode, error) {
+	doc := &xmlNode{}
+	stack := []*xmlNode{doc}
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the xml body. error: %v", err)
+		}
+		parent := stack[len(stack)-1]
+		switch tok := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: tok.Name.Local}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space != "" && attr.Name.Space != "xmlns" {
+					// the prefixes of the namespaces may differ between the bodies
+					attr.Name.Local = attr.Name.Space + ":" + attr.Name.Local
+				} else if attr.Name.Space == "xmlns" {
+					attr.Name.Local = "xmlns:" + attr.Name.Local
+				}
+				attr.Name.Space = ""
+				node.attrs = append(node.attrs, attr)
+			}
+			sort.Slice(node.attrs, func(i, j int) bool {
+				return node.attrs[i].Name.Local < node.attrs[j].Name.Local
+			})
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.text += strings.TrimSpace(string(tok))
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("failed to parse the xml body. error: unclosed elements")
+	}
+	return doc, nil
+}
+
+// removePath removes the elements, attributes or texts selected by the XPath from the
+// document. Only the child steps with the element names or *, and the descendant step at the
+// start of the path are supported.
+func (n *xmlNode) removePath(path string) {
+	anyDepth := strings.HasPrefix(path, "//")
+	steps := strings.Split(strings.Trim(path, "/"), "/")
+	if len(steps) == 0 || steps[0] == "" {
+		return
+	}
+	n.remove(steps, anyDepth)
+}
+
+func (n *xmlNode) remove(steps []string, anyDepth bool) {
+	step := steps[0]
+	if len(steps) == 1 && strings.HasPrefix(step, "@") {
+		attrs := n.attrs[:0]
+		for _, attr := range n.attrs {
+			if step != "@*" && attr.Name.Local != step[1:] {
+				attrs = append(attrs, attr)
+			}
+		}
+		n.attrs = attrs
+	}
+	if len(steps) == 1 && step == "text()" {
+		n.text = ""
+	}
+
+	children := n.children[:0]
+	for _, child := range n.children {
+		if step == "*" || child.name == step {
+			if len(steps) == 1 {
+				continue
+			}
+			child.remove(steps[1:], false)
+		}
+		if anyDepth {
+			child.remove(steps, true)
+		}
+		children = append(children, child)
+	}
+	n.children = children
+}
+
+// String renders the document in its canonical form, with every element on its own line.
+func (n *xmlNode) String() string {
+	var b strings.Builder
+	for _, child := range n.children {
+		child.write(&b, 0)
+	}
+	return b.String()
+}
+
+func (n *xmlNode) write(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent + "<" + n.name)
+	for _, attr := range n.attrs {
+		b.WriteString(" " + attr.Name.Local + `="`)
+		xml.EscapeText(b, []byte(attr.Value))
+		b.WriteString(`"`)
+	}
+	if n.text == "" && len(n.children) == 0 {
+		b.WriteString("/>\n")
+		return
+	}
+	b.WriteString(">\n")
+	if n.text != "" {
+		b.WriteString(indent + "  ")
+		xml.EscapeText(b, []byte(n.text))
+		b.WriteString("\n")
+	}
+	for _, child := range n.children {
+		child.write(b, depth+1)
+	}
+	b.WriteString(indent + "</" + n.name + ">\n")
+}
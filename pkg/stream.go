@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// IsEventStream checks whether the content type is of a Server-Sent Events response.
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// ReadStream reads the events of a Server-Sent Events response, or the chunks of a chunked
+// response, until the body ends or max events are read. The delays of the events are measured
+// from start. The events read before an error, such as the timeout of the client, are returned.
+func ReadStream(body io.Reader, sse bool, max int, start time.Time) []models.HttpStreamEvent {
+	var events []models.HttpStreamEvent
+	if sse {
+		reader := bufio.NewReader(body)
+		var lines []string
+		for len(events) < max {
+			line, err := reader.ReadString('\n')
+			line = strings.TrimRight(line, "\r\n")
+			if line == "" && len(lines) > 0 {
+				events = append(events, models.HttpStreamEvent{
+					Data:  strings.Join(lines, "\n"),
+					Delay: time.Since(start).Milliseconds(),
+				})
+				lines = nil
+			} else if line != "" {
+				lines = append(lines, line)
+			}
+			if err != nil {
+				break
+			}
+		}
+		return events
+	}
+
+	// the chunks are read as they arrive, though the chunks arriving together are merged
+	buf := make([]byte, 32*1024)
+	for len(events) < max {
+		n, err := body.Read(buf)
+		if n > 0 {
+			events = append(events, models.HttpStreamEvent{
+				Data:  string(buf[:n]),
+				Delay: time.Since(start).Milliseconds(),
+			})
+		}
+		if err != nil {
+			break
+		}
+	}
+	return events
+}
+
+// streamResponse reads the streamed response of the testcase, stopping at the number of
+// recorded events, since the Server-Sent Events responses may never end.
+func streamResponse(tc models.TestCase, httpResp *http.Response, start time.Time) *models.HttpResp {
+	sse := IsEventStream(httpResp.Header.Get("Content-Type"))
+	events := ReadStream(httpResp.Body, sse, len(tc.HttpResp.StreamEvents), start)
+	return &models.HttpResp{
+		StatusCode:   httpResp.StatusCode,
+		Header:       ResponseHeader(httpResp),
+		StreamEvents: events,
+	}
+}
@@ -3,10 +3,14 @@ package pkg
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +42,16 @@ func ToYamlHttpHeader(httpHeader http.Header) map[string]string {
 	return header
 }
 
+// ResponseHeader returns the header of the http response along with its Transfer-Encoding,
+// which net/http moves out of the header, so that the chunked responses can be told apart.
+func ResponseHeader(resp *http.Response) map[string]string {
+	header := ToYamlHttpHeader(resp.Header)
+	if len(resp.TransferEncoding) > 0 {
+		header["Transfer-Encoding"] = strings.Join(resp.TransferEncoding, ",")
+	}
+	return header
+}
+
 func ToHttpHeader(mockHeader map[string]string) http.Header {
 	header := http.Header{}
 	for i, j := range mockHeader {
@@ -59,16 +73,23 @@ func IsTime(stringDate string) bool {
 	return err == nil
 }
 
-func SimulateHttp(tc models.TestCase, logger *zap.Logger, apiTimeout uint64) (*models.HttpResp, error) {
+// SimulateHttp sends the request of the testcase to the user application. The references to the
+// variables extracted from the earlier testcases are substituted in the url, headers and body.
+func SimulateHttp(tc models.TestCase, vars map[string]string, logger *zap.Logger, apiTimeout uint64) (*models.HttpResp, error) {
 	resp := &models.HttpResp{}
 
 	logger.Info("making a http request", zap.Any("test case id", tc.Name))
-	req, err := http.NewRequest(string(tc.HttpReq.Method), tc.HttpReq.URL, bytes.NewBufferString(tc.HttpReq.Body))
+	body, err := RequestBody(tc.HttpReq, vars)
+	if err != nil {
+		logger.Error("failed to rebuild the body of the http request from the yaml document", zap.Error(err))
+		return nil, err
+	}
+	req, err := http.NewRequest(string(tc.HttpReq.Method), SubstituteVars(tc.HttpReq.URL, vars), bytes.NewReader(body))
 	if err != nil {
 		logger.Error("failed to create a http request from the yaml document", zap.Error(err))
 		return nil, err
 	}
-	req.Header = ToHttpHeader(tc.HttpReq.Header)
+	req.Header = ToHttpHeader(SubstituteHeaderVars(tc.HttpReq.Header, vars))
 	req.Header.Set("KEPLOY_TEST_ID", tc.Name)
 	req.ProtoMajor = tc.HttpReq.ProtoMajor
 	req.ProtoMinor = tc.HttpReq.ProtoMinor
@@ -84,11 +105,19 @@ func SimulateHttp(tc models.TestCase, logger *zap.Logger, apiTimeout uint64) (*m
 		},
 	}
 
+	started := time.Now()
 	httpResp, err := client.Do(req)
 	if err != nil {
 		logger.Error("failed sending testcase request to app", zap.Error(err))
 		return nil, err
 	}
+	defer httpResp.Body.Close()
+
+	if len(tc.HttpResp.StreamEvents) > 0 {
+		resp = streamResponse(tc, httpResp, time.Now())
+		resp.Latency = time.Since(started).Milliseconds()
+		return resp, nil
+	}
 
 	respBody, err := io.ReadAll(httpResp.Body)
 	if err != nil {
@@ -99,7 +128,8 @@ func SimulateHttp(tc models.TestCase, logger *zap.Logger, apiTimeout uint64) (*m
 	resp = &models.HttpResp{
 		StatusCode: httpResp.StatusCode,
 		Body:       string(respBody),
-		Header:     ToYamlHttpHeader(httpResp.Header),
+		Header:     ResponseHeader(httpResp),
+		Latency:    time.Since(started).Milliseconds(),
 	}
 
 	return resp, nil
@@ -132,3 +162,42 @@ func GenerateRandomID() int {
 	id := rand.Intn(1000000000) // Adjust the range as needed
 	return id
 }
+
+// ReplaceHostToIP replaces the hostname of the url with the ip address, e.g. to reach the
+// user application running in a docker container. The original url is returned along with
+// the error when it can't be replaced.
+func ReplaceHostToIP(currentURL string, ipAddress string) (string, error) {
+	// Parse the current URL
+	parsedURL, err := url.Parse(currentURL)
+	if err != nil {
+		return currentURL, err
+	}
+
+	if ipAddress == "" {
+		return currentURL, errors.New("the ip address of the user application is not known")
+	}
+
+	// Replace hostname with the IP address
+	parsedURL.Host = strings.Replace(parsedURL.Host, parsedURL.Hostname(), ipAddress, 1)
+
+	// Return the modified URL
+	return parsedURL.String(), nil
+}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// PathTemplate normalises the path of a request into the template of its route, by
+// collapsing the numeric and UUID segments into {id}. e.g. /users/42/orders becomes
+// /users/{id}/orders.
+func PathTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if _, err := strconv.ParseInt(segment, 10, 64); err == nil || uuidRegex.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
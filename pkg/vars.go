@@ -0,0 +1,181 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/pkg/models"
+)
+
+// varPattern matches the references to the variables extracted from the earlier testcases,
+// e.g. {{token}}.
+var varPattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_\-]*)\s*}}`)
+
+// RedactedPattern matches the placeholders of the redacted secrets, e.g.
+// {{REDACTED_1a2b3c4d5e6f}}. The placeholders are variables, so that the secrets can be passed
+// back at replay.
+var RedactedPattern = regexp.MustCompile(`{{REDACTED_[0-9a-f]{12}}}`)
+
+// RedactedPlaceholder returns the placeholder of the redacted secret. The same secret is always
+// replaced with the same placeholder, so that its uses across the testcases and mocks stay
+// linked. The salt keeps the placeholders from being matched against guessed secrets.
+func RedactedPlaceholder(secret, salt string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return "{{REDACTED_" + hex.EncodeToString(sum[:6]) + "}}"
+}
+
+// SecretVars returns the values of the redacted secrets, read from the environment variables
+// named after their placeholders, e.g. REDACTED_1a2b3c4d5e6f for {{REDACTED_1a2b3c4d5e6f}}.
+func SecretVars() map[string]string {
+	vars := map[string]string{}
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if ref := "{{" + name + "}}"; RedactedPattern.FindString(ref) == ref {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// MatchRedacted reports whether the recorded data with the placeholders of the redacted secrets
+// matches the actual data. The placeholders of the secrets passed in the environment are
+// replaced with them, and the others match any data. The recorded data without placeholders
+// isn't matched, since the callers compare it as it is.
+func MatchRedacted(recorded, actual []byte) bool {
+	if !RedactedPattern.Match(recorded) {
+		return false
+	}
+	recorded = []byte(SubstituteVars(string(recorded), SecretVars()))
+	locs := RedactedPattern.FindAllIndex(recorded, -1)
+	if len(locs) == 0 {
+		return bytes.Equal(recorded, actual)
+	}
+
+	// the parts between the placeholders appear in the actual data in their order, with the
+	// first and the last parts at its ends
+	first, last := recorded[:locs[0][0]], recorded[locs[len(locs)-1][1]:]
+	if len(first)+len(last) > len(actual) || !bytes.HasPrefix(actual, first) || !bytes.HasSuffix(actual, last) {
+		return false
+	}
+	rest := actual[len(first) : len(actual)-len(last)]
+	for i := 1; i < len(locs); i++ {
+		part := recorded[locs[i-1][1]:locs[i][0]]
+		j := bytes.Index(rest, part)
+		if j == -1 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+	return true
+}
+
+// SubstituteVars replaces the references to the variables in s with their values. The
+// references to unknown variables are kept as they are.
+func SubstituteVars(s string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(s, "{{") {
+		return s
+	}
+	return varPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varPattern.FindStringSubmatch(ref)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// SubstituteHeaderVars returns a copy of the headers with the variables substituted in
+// their values.
+func SubstituteHeaderVars(header map[string]string, vars map[string]string) map[string]string {
+	if len(vars) == 0 {
+		return header
+	}
+	res := make(map[string]string, len(header))
+	for k, v := range header {
+		res[k] = SubstituteVars(v, vars)
+	}
+	return res
+}
+
+// ExtractVars stores the values of the response fields declared by the extractions of the
+// testcase into vars. An extraction maps the name of a variable to either "header.<name>" or
+// "body.<path>", where the path is the dot separated keys and array indexes of a JSON body,
+// e.g. "body.data.items.0.id".
+func ExtractVars(extract map[string]string, resp *models.HttpResp, vars map[string]string) error {
+	var errs []string
+	for name, path := range extract {
+		value, err := extractValue(path, resp)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		vars[name] = value
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to extract the variables %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func extractValue(path string, resp *models.HttpResp) (string, error) {
+	switch {
+	case strings.HasPrefix(path, "header."):
+		key := strings.TrimPrefix(path, "header.")
+		for k, v := range resp.Header {
+			if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(key) {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("the response doesn't have the header %s", key)
+	case path == "body":
+		return resp.Body, nil
+	case strings.HasPrefix(path, "body."):
+		// the numbers are decoded as they are written, so that the large ids keep their digits
+		decoder := json.NewDecoder(bytes.NewBufferString(resp.Body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return "", fmt.Errorf("the response body is not a valid json")
+		}
+		for _, key := range strings.Split(strings.TrimPrefix(path, "body."), ".") {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				field, ok := v[key]
+				if !ok {
+					return "", fmt.Errorf("the response body doesn't have the field %s", path)
+				}
+				value = field
+			case []interface{}:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					return "", fmt.Errorf("the response body doesn't have the field %s", path)
+				}
+				value = v[i]
+			default:
+				return "", fmt.Errorf("the response body doesn't have the field %s", path)
+			}
+		}
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case nil:
+			return "", nil
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("the path %s should start with header. or body.", path)
+}
@@ -0,0 +1,51 @@
+package pkg
+
+import "testing"
+
+func TestMatchRedacted(t *testing.T) {
+	secret := RedactedPlaceholder("s3cret", "")
+	other := RedactedPlaceholder("other", "")
+
+	tests := []struct {
+		name     string
+		recorded string
+		actual   string
+		// env are the secrets passed in the environment
+		env  map[string]string
+		want bool
+	}{
+		{name: "equal data without placeholders", recorded: "AUTH s3cret", actual: "AUTH s3cret", want: false},
+		{name: "placeholder in the middle", recorded: "AUTH " + secret + "\r\n", actual: "AUTH s3cret\r\n", want: true},
+		{name: "placeholder at the ends", recorded: secret + " and " + other, actual: "a and b", want: true},
+		{name: "only a placeholder", recorded: secret, actual: "anything", want: true},
+		{name: "other prefix", recorded: "AUTH " + secret, actual: "PING s3cret", want: false},
+		{name: "other suffix", recorded: secret + "\r\n", actual: "s3cret\n", want: false},
+		{name: "parts out of order", recorded: secret + "a" + other + "b" + secret, actual: "xbyaz", want: false},
+		{name: "parts overlapping the ends", recorded: "ab" + secret + "ba", actual: "aba", want: false},
+		{name: "binary data", recorded: "p\x00\x00\x00\x1d" + secret + "\x00", actual: "p\x00\x00\x00\x1d\xff\xfe\x00", want: true},
+		{
+			name:     "secret passed in the environment",
+			recorded: "AUTH " + secret,
+			actual:   "AUTH s3cret",
+			env:      map[string]string{secret[2 : len(secret)-2]: "s3cret"},
+			want:     true,
+		},
+		{
+			name:     "other secret passed in the environment",
+			recorded: "AUTH " + secret,
+			actual:   "AUTH guess",
+			env:      map[string]string{secret[2 : len(secret)-2]: "s3cret"},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if got := MatchRedacted([]byte(tt.recorded), []byte(tt.actual)); got != tt.want {
+				t.Errorf("MatchRedacted(%q, %q) = %v, want %v", tt.recorded, tt.actual, got, tt.want)
+			}
+		})
+	}
+}